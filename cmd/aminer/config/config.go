@@ -2,16 +2,29 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+
+	"github.com/ngeorgiadis/community-discovery/internal/domination"
+	"github.com/ngeorgiadis/community-discovery/internal/settings"
 )
 
 type AppConfig struct {
-	NodesCSVFile   string `json:"nodesCSVFile"`
-	EdgesCSVFile   string `json:"edgesCSVFile"`
-	BaseOutputPath string `json:"baseOutputPath"`
-	Dimensions     int    `json:"dimensions"`
-	GridSize       []int  `json:"gridSize"`
-	Approximate    bool   `json:"approximate"`
+	NodesCSVFile   string                    `json:"nodesCSVFile"`
+	EdgesCSVFile   string                    `json:"edgesCSVFile"`
+	BaseOutputPath string                    `json:"baseOutputPath"`
+	Dimensions     int                       `json:"dimensions"`
+	GridSize       domination.GridSize       `json:"gridSize"`
+	GridMode       string                    `json:"gridMode"`
+	GridSample     int                       `json:"gridSample"`
+	Workers        int                       `json:"workers"`
+	Strictness     string                    `json:"strictness"`
+	Approximate    bool                      `json:"approximate"`
+	Mode           string                    `json:"mode"`
+	Streaming      bool                      `json:"streaming"`
+	Directions     []string                  `json:"directions"`
+	Algorithm      string                    `json:"algorithm"`
+	Columns        *domination.ColumnMapping `json:"columns"`
 }
 
 func New(configFile string) (*AppConfig, error) {
@@ -27,5 +40,46 @@ func New(configFile string) (*AppConfig, error) {
 		return nil, err
 	}
 
+	err = c.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", configFile, err)
+	}
+
 	return &c, nil
 }
+
+// Validate checks the settings up front and
+// returns every problem found at once
+func (c *AppConfig) Validate() error {
+	p := &settings.Problems{}
+
+	dimensions := c.Dimensions
+	if c.Columns != nil {
+		if c.Dimensions != 0 && c.Dimensions != len(c.Columns.Attrs) {
+			p.Addf("dimensions is %v but %v attribute columns are given", c.Dimensions, len(c.Columns.Attrs))
+		}
+		dimensions = len(c.Columns.Attrs)
+		p.Positive("columns.attrs length", dimensions)
+	} else {
+		_, err := domination.AminerColumns(c.Dimensions)
+		p.Check("dimensions", err)
+	}
+
+	p.GridSize("gridSize", c.GridSize, dimensions)
+	p.Directions("directions", c.Directions, dimensions)
+
+	p.OneOf("mode", c.Mode, "score", "skyline")
+
+	_, err := domination.ParseGridMode(c.GridMode)
+	p.Check("gridMode", err)
+	_, err = domination.ParseAlgorithm(c.Algorithm)
+	p.Check("algorithm", err)
+	_, err = domination.ParseStrictness(c.Strictness)
+	p.Check("strictness", err)
+
+	p.InputFile("nodesCSVFile", c.NodesCSVFile, false)
+	p.InputFile("edgesCSVFile", c.EdgesCSVFile, true)
+	p.OutputDir("baseOutputPath", c.BaseOutputPath)
+
+	return p.Err()
+}
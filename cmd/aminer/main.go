@@ -1,17 +1,13 @@
 package main
 
 import (
-	"encoding/csv"
 	"fmt"
-	"log"
-	"math"
 	"os"
 	"path"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/ngeorgiadis/community-discovery/cmd/aminer/config"
+	"github.com/ngeorgiadis/community-discovery/internal/community"
 	"github.com/ngeorgiadis/community-discovery/internal/domination"
 )
 
@@ -36,138 +32,78 @@ func main() {
 	}
 
 	ds := domination.New()
-	dsFilePath := path.Join(outputBasePath, "domination.txt")
-
-	defaultReader := &AminerDatasetReader{Dimensions: a.Dimensions}
-
-	ds.Calc(defaultReader, a.NodesCSVFile, dsFilePath, a.Approximate, a.GridSize)
-}
-
-type AminerDatasetReader struct {
-	Dimensions int
-}
-
-func (adr *AminerDatasetReader) ReadDataset(filename string) (map[int]domination.DataRow, *domination.DataStats, []domination.DataPoint) {
-	f, _ := os.Open(filename)
-	r := csv.NewReader(f)
-
-	fmt.Println("start")
-	fmt.Println("reading...")
+	if a.Workers > 0 {
+		ds.Workers = a.Workers
+	}
+	ds.GridSample = a.GridSample
 
-	recs, err := r.ReadAll()
+	ds.Directions, err = domination.ParseDirections(a.Directions)
 	if err != nil {
-		log.Fatal(err)
+		panic(err)
 	}
 
-	res := map[int]domination.DataRow{}
-	stats := &domination.DataStats{
-		Max:       make([]int, adr.Dimensions),
-		Min:       make([]int, adr.Dimensions),
-		Histogram: make([]map[int]int, adr.Dimensions),
-		Count:     0,
+	ds.GridMode, err = domination.ParseGridMode(a.GridMode)
+	if err != nil {
+		panic(err)
 	}
 
-	for i := range stats.Max {
-		stats.Max[i] = math.MinInt64
-		stats.Min[i] = math.MaxInt64
+	ds.Algorithm, err = domination.ParseAlgorithm(a.Algorithm)
+	if err != nil {
+		panic(err)
 	}
 
-	unique := map[string]int{}
-
-	for i, row := range recs {
-		if i == 0 {
-			continue
-		}
-
-		id, _ := strconv.Atoi(row[0])
-
-		pc, _ := strconv.Atoi(row[2])
-		cn, _ := strconv.Atoi(row[3])
-		hi, _ := strconv.Atoi(row[4])
-		pi, _ := strconv.ParseFloat(row[5], 64)
-		pi = math.Trunc(pi)
-
-		attrs := make([]int, adr.Dimensions)
-
-		switch adr.Dimensions {
-		case 2:
-			attrs[0] = pc
-			attrs[1] = cn
-		case 3:
-			attrs[0] = pc
-			attrs[1] = cn
-			attrs[2] = hi
-		case 4:
-			attrs[0] = pc
-			attrs[1] = cn
-			attrs[2] = hi
-			attrs[3] = int(pi)
-		default:
-			panic("dataset dimensions should be 2, 3 or 4")
-		}
-
-		// stats max
-		{
-
-			for j := range attrs {
-				if attrs[j] > stats.Max[j] {
-					stats.Max[j] = attrs[j]
-				}
-			}
+	dsFilePath := path.Join(outputBasePath, "domination.txt")
 
-			for j := range attrs {
-				if attrs[j] < stats.Min[j] {
-					stats.Min[j] = attrs[j]
-				}
-			}
-		}
+	strictness, err := domination.ParseStrictness(a.Strictness)
+	if err != nil {
+		panic(err)
+	}
 
-		// histogram
-		{
-			for i, a := range attrs {
-				if stats.Histogram[i] == nil {
-					stats.Histogram[i] = map[int]int{}
-				}
-				stats.Histogram[i][a]++
-			}
+	columns, err := domination.AminerColumns(a.Dimensions)
+	if a.Columns != nil {
+		columns, err = *a.Columns, nil
+		if a.Dimensions != 0 && a.Dimensions != len(columns.Attrs) {
+			err = fmt.Errorf("dimensions is %v but %v attribute columns are given", a.Dimensions, len(columns.Attrs))
 		}
+	}
+	if err != nil {
+		panic(err)
+	}
 
-		// unique
-		{
-			attrKey := ""
-			for i := range attrs {
-				attrKey += fmt.Sprintf("%v|", attrs[i])
-			}
-
-			unique[attrKey]++
-		}
+	defaultReader := &domination.CSVDatasetReader{
+		Columns:    columns,
+		Strictness: strictness,
+		Directions: ds.Directions,
+	}
 
-		res[id] = domination.DataRow{
-			ID:    id,
-			Name:  row[1],
-			Attrs: attrs,
+	switch a.Mode {
+	case "skyline":
+		skylineFilePath := path.Join(outputBasePath, "skyline.txt")
+		err = ds.CalcSkyline(defaultReader, a.NodesCSVFile, skylineFilePath, a.GridSize)
+	default:
+		if a.Streaming {
+			err = ds.CalcStream(defaultReader, a.NodesCSVFile, dsFilePath, a.Approximate, a.GridSize)
+			break
 		}
-
+		err = ds.Calc(defaultReader, a.NodesCSVFile, dsFilePath, a.Approximate, a.GridSize)
+	}
+	if err != nil {
+		panic(err)
 	}
 
-	dataPoints := []domination.DataPoint{}
-
-	for k, v := range unique {
-
-		attrs := strings.Split(strings.TrimRight(k, "|"), "|")
+	// load the coauthor graph and check that
+	// its authors join with the scores
+	if a.EdgesCSVFile != "" && a.Mode != "skyline" {
+		g, err := community.LoadEdges(a.EdgesCSVFile)
+		if err != nil {
+			panic(err)
+		}
 
-		a := []int{}
-		for i := range attrs {
-			iv, _ := strconv.Atoi(attrs[i])
-			a = append(a, iv)
+		dom, err := community.LoadScores(dsFilePath)
+		if err != nil {
+			panic(err)
 		}
 
-		dataPoints = append(dataPoints, domination.DataPoint{
-			Count: v,
-			Attrs: a,
-		})
+		fmt.Printf("graph: %v authors, %v edges, %v without a domination score\n", g.Order(), g.Size(), len(community.Unscored(g, dom)))
 	}
-
-	stats.Count = len(res)
-	return res, stats, dataPoints
 }
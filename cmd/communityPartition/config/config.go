@@ -0,0 +1,45 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+type AppConfig struct {
+	GraphFile string `json:"graphFile"`
+
+	// DomFile is the domination file to rank the authors by. When
+	// empty, it is the domination.txt of InputPath, or of the latest
+	// run in InputPath when it is the output folder of cmd/aminer.
+	DomFile   string `json:"domFile"`
+	InputPath string `json:"inputPath"`
+
+	// BaseOutputPath defaults to the folder of the domination file
+	BaseOutputPath string `json:"baseOutputPath"`
+
+	Hop int `json:"hop"`
+
+	// Top limits the search to the first authors of
+	// the domination order, 0 searches all of them
+	Top         int   `json:"top"`
+	CheckPoints []int `json:"checkPoints"`
+}
+
+func New(configFile string) (*AppConfig, error) {
+
+	b, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	c := AppConfig{
+		Hop:         1,
+		CheckPoints: []int{5000, 10000, 50000, 100000, 500000},
+	}
+	err = json.Unmarshal(b, &c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/ngeorgiadis/community-discovery/cmd/communityPartition/config"
+	"github.com/ngeorgiadis/community-discovery/internal/community"
+)
+
+func main() {
+
+	a, err := config.New("settings.json")
+	if err != nil {
+		panic(err)
+	}
+
+	domFile := a.DomFile
+	if domFile == "" {
+		domFile, err = findDomFile(a.InputPath)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	outputBasePath := a.BaseOutputPath
+	if outputBasePath == "" {
+		outputBasePath = path.Dir(domFile)
+	}
+
+	fmt.Printf("graph_file: %v\n", a.GraphFile)
+	fmt.Printf("dom_file: %v\n", domFile)
+	fmt.Printf("hop: %v\n", a.Hop)
+	fmt.Println("")
+
+	t1 := time.Now()
+	dom, err := community.LoadScores(domFile)
+	if err != nil {
+		panic(err)
+	}
+
+	// every scored author is a vertex, even without coauthors
+	b := community.NewBuilder()
+	for id := range dom {
+		b.AddNode(id)
+	}
+	err = b.LoadEdges(a.GraphFile)
+	if err != nil {
+		panic(err)
+	}
+	g := b.Build()
+	fmt.Printf("graph: %v nodes, %v edges, loaded in %v\n", g.Order(), g.Size(), time.Since(t1))
+
+	ranked := community.Rank(dom)
+	top := len(ranked)
+	if a.Top > 0 && a.Top < top {
+		top = a.Top
+	}
+
+	checkPoints := append([]int{}, a.CheckPoints...)
+	sort.Ints(checkPoints)
+
+	t1 = time.Now()
+	p := community.NewSearcher(g, dom, a.Hop).Partition()
+	results := community.Results{}
+
+	check1 := time.Now()
+	checkpointTimes := time.Duration(0)
+	cpi := 0
+
+	for idx, id := range ranked[:top] {
+
+		if cpi < len(checkPoints) && idx+1 >= checkPoints[cpi] {
+			checkpointTimes += time.Since(check1)
+			fmt.Println("")
+			fmt.Printf("checkpoint: %v, top-%v, egonet time: %v, k-core time:%v ( %v ), %v\n",
+				checkpointTimes.Seconds(), checkPoints[cpi], p.EgoTime.Seconds(), p.CoreTime.Seconds(), len(results), a.Hop)
+			cpi++
+			check1 = time.Now()
+		}
+
+		c, err := p.Community(id)
+		if err != nil {
+			panic(err)
+		}
+		if c == nil {
+			continue
+		}
+
+		results = append(results, community.NewRow(c, idx+1))
+
+		if len(results)%1000 == 0 {
+			fmt.Print(".")
+		}
+	}
+
+	fmt.Println("")
+	fmt.Printf("total communities: %v, egonet time: %v, k-core time: %v, done in: %v\n",
+		len(results), p.EgoTime.Seconds(), p.CoreTime.Seconds(), time.Since(t1))
+	fmt.Println("")
+
+	results.Transform()
+
+	stmp := time.Now().Format("20060102-150405")
+	resultsFilePath := path.Join(outputBasePath, fmt.Sprintf("results-%v-%v-%v_DEBUG.csv", top, a.Hop, stmp))
+
+	fd, err := os.Create(resultsFilePath)
+	if err != nil {
+		panic(err)
+	}
+	err = results.Write(fd)
+	if err != nil {
+		fd.Close()
+		panic(err)
+	}
+	err = fd.Close()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("results: %v\n", resultsFilePath)
+}
+
+// findDomFile returns the domination file of inputPath, which is either
+// a run folder of cmd/aminer or the output folder holding the runs, in
+// which case the latest run is taken
+func findDomFile(inputPath string) (string, error) {
+	domFile := path.Join(inputPath, "domination.txt")
+	if _, err := os.Stat(domFile); err == nil {
+		return domFile, nil
+	}
+
+	entries, err := os.ReadDir(inputPath)
+	if err != nil {
+		return "", err
+	}
+
+	// the run folders are named by their timestamp,
+	// so the latest is the last one in name order
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !entries[i].IsDir() {
+			continue
+		}
+
+		domFile = path.Join(inputPath, entries[i].Name(), "domination.txt")
+		if _, err := os.Stat(domFile); err == nil {
+			return domFile, nil
+		}
+	}
+
+	return "", fmt.Errorf("no domination.txt found in %v or its runs", inputPath)
+}
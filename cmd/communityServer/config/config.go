@@ -0,0 +1,33 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+type AppConfig struct {
+	GraphFile       string `json:"graphFile"`
+	AuthorsFile     string `json:"authorsFile"`
+	DomFile         string `json:"domFile"`
+	Address         string `json:"address"`
+	ShutdownTimeout int    `json:"shutdownTimeout"`
+}
+
+func New(configFile string) (*AppConfig, error) {
+
+	b, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	c := AppConfig{
+		Address:         ":9090",
+		ShutdownTimeout: 10,
+	}
+	err = json.Unmarshal(b, &c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
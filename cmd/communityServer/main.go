@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ngeorgiadis/community-discovery/cmd/communityServer/config"
+	"github.com/ngeorgiadis/community-discovery/internal/community"
+)
+
+func main() {
+
+	a, err := config.New("settings.json")
+	if err != nil {
+		panic(err)
+	}
+
+	t1 := time.Now()
+
+	authors := map[int]*community.Author{}
+	if a.AuthorsFile != "" {
+		fmt.Println("adding metadata (author stats)... ")
+		authors, err = community.LoadAuthors(a.AuthorsFile)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	dom, err := community.LoadScores(a.DomFile)
+	if err != nil {
+		panic(err)
+	}
+
+	// every author is a vertex, even without coauthors,
+	// the same as in the graph of the api
+	b := community.NewBuilder()
+	for id := range authors {
+		b.AddNode(id)
+	}
+	for id := range dom {
+		b.AddNode(id)
+	}
+
+	err = b.LoadEdges(a.GraphFile)
+	if err != nil {
+		panic(err)
+	}
+
+	g := b.Build()
+	fmt.Printf("graph: %v nodes, %v edges, loaded in %v\n", g.Order(), g.Size(), time.Since(t1))
+
+	s := &server{
+		searcher: community.NewSearcher(g, dom, 1),
+		ranked:   community.Rank(dom),
+		authors:  authors,
+	}
+
+	srv := &http.Server{
+		Addr:    a.Address,
+		Handler: s.routes(),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.ListenAndServe()
+	}()
+	fmt.Printf("listening on %v\n", a.Address)
+
+	select {
+	case err := <-errc:
+		panic(err)
+	case <-ctx.Done():
+	}
+
+	fmt.Println("shutting down...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(a.ShutdownTimeout)*time.Second)
+	defer cancel()
+
+	err = srv.Shutdown(ctx)
+	if err != nil {
+		panic(err)
+	}
+}
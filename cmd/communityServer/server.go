@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ngeorgiadis/community-discovery/internal/community"
+)
+
+type server struct {
+	searcher *community.Searcher
+
+	// ranked holds the node ids by descending domination score
+	ranked  []int
+	authors map[int]*community.Author
+}
+
+func (s *server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", s.health)
+	mux.HandleFunc("/api/comm/over/", s.overlapping)
+	mux.HandleFunc("/api/comm/non/", s.nonOverlapping)
+	return mux
+}
+
+func (s *server) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]interface{}{
+		"status": "ok",
+		"nodes":  s.searcher.Graph.Order(),
+		"edges":  s.searcher.Graph.Size(),
+	})
+}
+
+// params parses the :init/:hop/:max part of the request path
+func params(r *http.Request, prefix string) (int, int, bool, error) {
+	p := strings.Split(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	if len(p) != 3 {
+		return 0, 0, false, fmt.Errorf("expected %v:init/:hop/:max", prefix)
+	}
+
+	init, err := strconv.Atoi(p[0])
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("init: %w", err)
+	}
+
+	hop, err := strconv.Atoi(p[1])
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("hop: %w", err)
+	}
+	if hop < 0 {
+		return 0, 0, false, fmt.Errorf("hop should not be negative, got %v", hop)
+	}
+
+	return init, hop, p[2] == "max", nil
+}
+
+// search returns a copy of the searcher of s for the request parameters
+func (s *server) search(hop int, max bool) *community.Searcher {
+	search := *s.searcher
+	search.Hops = hop
+	search.WholeEgonet = !max
+	return &search
+}
+
+// overlapping serves the community of init, the max k-core of its
+// egonet or the whole egonet, like find_community_overlapping
+func (s *server) overlapping(w http.ResponseWriter, r *http.Request) {
+	init, hop, max, err := params(r, "/api/comm/over/")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	t1 := time.Now()
+
+	c, err := s.search(hop, max).Community(init)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	stats := statsJSON(c)
+	stats["original_index"] = nil
+
+	fmt.Printf("over init: %v, hop: %v, max: %v, done in: %v\n", init, hop, max, time.Since(t1))
+
+	writeJSON(w, map[string]interface{}{
+		"stats":     stats,
+		"community": s.metaGraph(c.Graph),
+	})
+}
+
+// nonOverlapping serves the community of init when the communities are
+// found one after the other in domination score order, each leaving out
+// the nodes of the ones before it, like find_community_non_overlapping.
+// The search stops at the community of init, or at the one that takes
+// init in, when init comes later in the order.
+func (s *server) nonOverlapping(w http.ResponseWriter, r *http.Request) {
+	init, hop, max, err := params(r, "/api/comm/non/")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := s.searcher.Graph.Vertex(init); !ok {
+		http.Error(w, fmt.Sprintf("node %v not in the graph", init), http.StatusNotFound)
+		return
+	}
+
+	fmt.Printf("getting communities with params: %vhop, init-%v\n", hop, init)
+	t1 := time.Now()
+
+	p := s.search(hop, max).Partition()
+
+	var c *community.Community
+	index := 0
+	i := 1
+	for idx, id := range s.ranked {
+		if p.Visited(init) {
+			break
+		}
+
+		next, err := p.Community(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if next == nil {
+			continue
+		}
+
+		// the api numbers the max k-cores by their place in the
+		// domination order and the egonets by their own count
+		c = next
+		index = i
+		if max {
+			index = idx + 1
+		}
+		i++
+
+		if id == init {
+			break
+		}
+	}
+
+	fmt.Printf("%v, completed in %v\n", i, time.Since(t1))
+
+	if c == nil {
+		http.Error(w, fmt.Sprintf("no community found for node %v", init), http.StatusNotFound)
+		return
+	}
+
+	stats := statsJSON(c)
+	stats["original_index"] = index
+	if max {
+		stats["avg_clustering"] = finite(c.Graph.AvgClustering())
+		stats["density"] = finite(c.Graph.Density())
+		stats["avg_degree"] = finite(c.Graph.AvgDegree())
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"stats":     stats,
+		"community": s.metaGraph(c.Graph),
+	})
+}
+
+// statsJSON returns the stats of c with the keys of get_graph_stats
+func statsJSON(c *community.Community) map[string]interface{} {
+	return map[string]interface{}{
+		"init":             c.Init,
+		"number_of_nodes":  c.Stats.NumberOfNodes,
+		"ratio_max_k_core": finite(c.Stats.RatioMaxKCore),
+		"max_k_core":       c.Stats.MaxKCore,
+		"max_stddev":       finite(c.Stats.MaxStddev),
+		"e2":               finite(c.Stats.E2),
+		"e4":               finite(c.Stats.E4),
+	}
+}
+
+// finite returns nil for the values JSON cannot hold, like the
+// e2 and e4 of a community whose nodes all have the top score
+func finite(v float64) interface{} {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return v
+}
+
+// metaGraph is the JSON form of a MetaGraph of the api, with vertices
+// numbered from 1
+type metaGraph struct {
+	Graph         simpleGraph                       `json:"graph"`
+	VProps        map[string]map[string]interface{} `json:"vprops"`
+	EProps        map[string]interface{}            `json:"eprops"`
+	GProps        map[string]interface{}            `json:"gprops"`
+	WeightField   string                            `json:"weightfield"`
+	DefaultWeight float64                           `json:"defaultweight"`
+	MetaIndex     map[string]interface{}            `json:"metaindex"`
+	Indices       []string                          `json:"indices"`
+}
+
+type simpleGraph struct {
+	NE       int     `json:"ne"`
+	FAdjList [][]int `json:"fadjlist"`
+}
+
+// metaGraph returns the community g with the author
+// fields and the domination score of every vertex
+func (s *server) metaGraph(g *community.Graph) *metaGraph {
+	mg := &metaGraph{
+		Graph: simpleGraph{
+			NE:       g.Size(),
+			FAdjList: make([][]int, g.Order()),
+		},
+		VProps:        map[string]map[string]interface{}{},
+		EProps:        map[string]interface{}{},
+		GProps:        map[string]interface{}{},
+		WeightField:   "weight",
+		DefaultWeight: 1,
+		MetaIndex:     map[string]interface{}{},
+		Indices:       []string{},
+	}
+
+	for v, id := range g.IDs() {
+		neighbors := g.Neighbors(v)
+		mg.Graph.FAdjList[v] = make([]int, len(neighbors))
+		for i, u := range neighbors {
+			mg.Graph.FAdjList[v][i] = u + 1
+		}
+
+		props := map[string]interface{}{}
+		if a, ok := s.authors[id]; ok {
+			props["id"] = a.ID
+			props["name"] = a.Name
+			props["pc"] = a.PC
+			props["cn"] = a.CN
+			props["hi"] = a.HI
+			props["pi"] = a.PI
+		}
+		if d, ok := s.searcher.Dom[id]; ok {
+			props["dom"] = d
+		}
+		mg.VProps[strconv.Itoa(v+1)] = props
+	}
+
+	return mg
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		fmt.Printf("writing response: %v\n", err)
+	}
+}
@@ -2,16 +2,43 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"math"
+
+	"github.com/ngeorgiadis/community-discovery/internal/domination"
+	"github.com/ngeorgiadis/community-discovery/internal/generator"
+	"github.com/ngeorgiadis/community-discovery/internal/settings"
 )
 
 type AppConfig struct {
-	DatasetType       string `json:"datasetType"`
-	DatasetSize       int    `json:"datasetSize"`
-	DatasetDimensions int    `json:"datasetDimensions"`
-	BaseOutputPath    string `json:"baseOutputPath"`
-	GridSize          []int  `json:"gridSize"`
-	Approximate       bool   `json:"approximate"`
+	DatasetType       string              `json:"datasetType"`
+	DatasetSize       int                 `json:"datasetSize"`
+	DatasetDimensions int                 `json:"datasetDimensions"`
+	BaseOutputPath    string              `json:"baseOutputPath"`
+	GridSize          domination.GridSize `json:"gridSize"`
+	GridMode          string              `json:"gridMode"`
+	GridSample        int                 `json:"gridSample"`
+	Workers           int                 `json:"workers"`
+	Strictness        string              `json:"strictness"`
+	Directions        []string            `json:"directions"`
+	Algorithm         string              `json:"algorithm"`
+	Approximate       bool                `json:"approximate"`
+
+	// Seed seeds the random values, a dataset generated again with
+	// the same seed and settings is the same. A random seed is used
+	// when it is not set.
+	Seed *int64 `json:"seed"`
+
+	// the parameters of the distributions: the values are drawn
+	// from [0, valueRange), correlation sets how close the
+	// ANTI_CORRELATED points lie to their plane, and a CLUSTERED
+	// dataset has clusters clusters with a standard deviation of
+	// spread, both as fractions of the value range
+	ValueRange  int     `json:"valueRange"`
+	Correlation float64 `json:"correlation"`
+	Clusters    int     `json:"clusters"`
+	Spread      float64 `json:"spread"`
 }
 
 func New(configFile string) (*AppConfig, error) {
@@ -21,11 +48,62 @@ func New(configFile string) (*AppConfig, error) {
 		return nil, err
 	}
 
-	c := AppConfig{}
+	defaults := generator.DefaultParams("", 0)
+	c := AppConfig{
+		ValueRange:  defaults.ValueRange,
+		Correlation: defaults.Correlation,
+		Clusters:    defaults.Clusters,
+		Spread:      defaults.Spread,
+	}
 	err = json.Unmarshal(b, &c)
 	if err != nil {
 		return nil, err
 	}
 
+	err = c.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", configFile, err)
+	}
+
 	return &c, nil
 }
+
+// Validate checks the settings up front and
+// returns every problem found at once
+func (c *AppConfig) Validate() error {
+	p := &settings.Problems{}
+
+	switch c.DatasetType {
+	case generator.Uniform, generator.Correlated:
+	case generator.AntiCorrelated:
+		p.Between("correlation", c.Correlation, 0, 1)
+	case generator.Clustered:
+		p.Positive("clusters", c.Clusters)
+		if !(c.Spread > 0) {
+			p.Addf("spread is %v, should be above 0", c.Spread)
+		}
+	default:
+		p.Addf("datasetType: unknown dataset type %q, expected UNIFORM, CORRELATED, ANTI_CORRELATED or CLUSTERED", c.DatasetType)
+	}
+
+	p.Positive("datasetSize", c.DatasetSize)
+	p.Positive("datasetDimensions", c.DatasetDimensions)
+	// the values are drawn as int32
+	if c.ValueRange < 1 || c.ValueRange > math.MaxInt32 {
+		p.Addf("valueRange is %v, should be from 1 to %v", c.ValueRange, math.MaxInt32)
+	}
+
+	p.GridSize("gridSize", c.GridSize, c.DatasetDimensions)
+	p.Directions("directions", c.Directions, c.DatasetDimensions)
+
+	_, err := domination.ParseGridMode(c.GridMode)
+	p.Check("gridMode", err)
+	_, err = domination.ParseAlgorithm(c.Algorithm)
+	p.Check("algorithm", err)
+	_, err = domination.ParseStrictness(c.Strictness)
+	p.Check("strictness", err)
+
+	p.OutputDir("baseOutputPath", c.BaseOutputPath)
+
+	return p.Err()
+}
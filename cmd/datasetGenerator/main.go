@@ -1,41 +1,59 @@
 package main
 
 import (
-	"encoding/csv"
+	"bufio"
 	"fmt"
-	"log"
-	"math"
+	"io"
 	"math/rand"
 	"os"
 	"path"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ngeorgiadis/community-discovery/cmd/datasetGenerator/config"
 	"github.com/ngeorgiadis/community-discovery/internal/domination"
+	"github.com/ngeorgiadis/community-discovery/internal/generator"
 )
 
-func correlated(mean float64, d int) []int {
-	res := make([]int, d)
-	for i := range res {
+// datasetName returns the name of the dataset of the settings a
+// generated with seed, its type, size, dimensions and seed
+func datasetName(a *config.AppConfig, seed int64) string {
+	return fmt.Sprintf("%v_%vx%v_seed%v", a.DatasetType, a.DatasetSize, a.DatasetDimensions, seed)
+}
 
-		nf := rand.NormFloat64()
-		for nf < 0 {
-			nf = rand.NormFloat64()
-		}
+// writeDataset writes the points of the dataset of the settings a,
+// drawn with seed, to w as tab separated id and attribute lines
+func writeDataset(w io.Writer, a *config.AppConfig, seed int64) error {
+	gen, err := generator.New(rand.New(rand.NewSource(seed)), generator.Params{
+		Type:       a.DatasetType,
+		Dimensions: a.DatasetDimensions,
 
-		res[i] = int(nf*25 + mean)
+		ValueRange:  a.ValueRange,
+		Correlation: a.Correlation,
+		Clusters:    a.Clusters,
+		Spread:      a.Spread,
+	})
+	if err != nil {
+		return err
 	}
-	return res
-}
 
-func uniform(d int) []int {
-	res := make([]int, d)
-	for i := range res {
-		res[i] = int(rand.Int31n(255))
+	bw := bufio.NewWriter(w)
+	for i := 0; i < a.DatasetSize; i++ {
+
+		v := gen.Point()
+
+		vs := ""
+		for _, vi := range v {
+			vs += fmt.Sprintf("%v\t", vi)
+		}
+		vs = strings.TrimSpace(vs)
+		fmt.Fprintf(bw, "%v\t%v\n", i, vs)
+
+		if i%10000 == 0 {
+			fmt.Print("+")
+		}
 	}
-	return res
+	return bw.Flush()
 }
 
 func main() {
@@ -45,13 +63,24 @@ func main() {
 		panic(err)
 	}
 
+	// the same seed gives the same dataset, it is
+	// picked at random and printed when not set
+	seed := time.Now().UnixNano()
+	if a.Seed != nil {
+		seed = *a.Seed
+	}
+	fmt.Printf("seed: %v\n", seed)
+
 	suffix := "exact"
 	if a.Approximate {
 		suffix = "approx"
 	}
 
-	datasetFilename := fmt.Sprintf("dataset_%v_%v.txt", a.DatasetType, time.Now().Format("20060102_150405"))
-	outputFile := fmt.Sprintf("domination_%v_%v_%v.txt", a.DatasetType, time.Now().Format("20060102_150405"), suffix)
+	// the names depend on the settings and the seed only, so
+	// generating again with the same ones gives the same files
+	name := datasetName(a, seed)
+	datasetFilename := fmt.Sprintf("dataset_%v.txt", name)
+	outputFile := fmt.Sprintf("domination_%v_%v.txt", name, suffix)
 	outputPath := path.Join(a.BaseOutputPath, outputFile)
 
 	err = os.MkdirAll(a.BaseOutputPath, 0777)
@@ -59,171 +88,64 @@ func main() {
 		panic(err.Error())
 	}
 
-	f, _ := os.Create(datasetFilename)
-	for i := 0; i < a.DatasetSize; i++ {
-
-		v := make([]int, a.DatasetDimensions)
-
-		switch a.DatasetType {
-		case "UNIFORM":
-			v = uniform(a.DatasetDimensions)
-		case "CORRELATED":
-			r := rand.NormFloat64()
-			for r < 0 {
-				r = rand.NormFloat64()
-			}
-			v = correlated(float64(r*50), a.DatasetDimensions)
-		}
-
-		vs := ""
-		for _, vi := range v {
-			vs += fmt.Sprintf("%v\t", vi)
-		}
-		vs = strings.TrimSpace(vs)
-		f.WriteString(fmt.Sprintf("%v\t%v\n", i, vs))
-
-		if i%10000 == 0 {
-			fmt.Print("+")
-		}
+	f, err := os.Create(datasetFilename)
+	if err != nil {
+		panic(err)
+	}
+	err = writeDataset(f, a, seed)
+	if err != nil {
+		panic(err)
+	}
+	err = f.Close()
+	if err != nil {
+		panic(err)
 	}
-	f.Close()
 	fmt.Println(".")
 
 	ds := domination.New()
-	syntheticReader := &SyntheticDatasetReader{
-		Dimensions: a.DatasetDimensions,
+	if a.Workers > 0 {
+		ds.Workers = a.Workers
 	}
+	ds.GridSample = a.GridSample
 
-	ds.Calc(syntheticReader, datasetFilename, outputPath, a.Approximate, a.GridSize)
-}
-
-type SyntheticDatasetReader struct {
-	Dimensions int
-}
-
-func (sdr *SyntheticDatasetReader) ReadDataset(filename string) (map[int]domination.DataRow, *domination.DataStats, []domination.DataPoint) {
-	f, _ := os.Open(filename)
-	r := csv.NewReader(f)
-	r.Comma = '\t'
-
-	fmt.Println("start")
-	fmt.Println("reading...")
-
-	recs, err := r.ReadAll()
+	ds.Directions, err = domination.ParseDirections(a.Directions)
 	if err != nil {
-		log.Fatal(err)
+		panic(err)
 	}
 
-	res := map[int]domination.DataRow{}
-	stats := &domination.DataStats{
-		Max:       make([]int, sdr.Dimensions),
-		Min:       make([]int, sdr.Dimensions),
-		Histogram: make([]map[int]int, sdr.Dimensions),
-		Count:     0,
+	ds.GridMode, err = domination.ParseGridMode(a.GridMode)
+	if err != nil {
+		panic(err)
 	}
 
-	for i := range stats.Max {
-		stats.Max[i] = math.MinInt64
-		stats.Min[i] = math.MaxInt64
+	ds.Algorithm, err = domination.ParseAlgorithm(a.Algorithm)
+	if err != nil {
+		panic(err)
 	}
 
-	// stats.Max = []int{math.MinInt64, math.MinInt64, math.MinInt64, math.MinInt64}
-	// stats.Min = []int{math.MaxInt64, math.MaxInt64, math.MaxInt64, math.MaxInt64}
-
-	unique := map[string]int{}
-
-	for i, row := range recs {
-		if i == 0 {
-			continue
-		}
-
-		attrs := make([]int, sdr.Dimensions)
-
-		var id int
-
-		for it, cell := range row {
-			v, _ := strconv.Atoi(cell)
-			if it == 0 {
-				id, _ = strconv.Atoi(row[0])
-				continue
-			}
-
-			attrs[it-1] = v
-		}
-
-		// pc, _ := strconv.Atoi(row[1])
-		// cn, _ := strconv.Atoi(row[2])
-		// hi, _ := strconv.Atoi(row[3])
-		// pi, _ := strconv.Atoi(row[4])
-
-		// attrs := []int{
-		// 	pc, cn, hi, pi,
-		// }
-
-		// stats max / min
-		{
-
-			for j := range attrs {
-				if attrs[j] > stats.Max[j] {
-					stats.Max[j] = attrs[j]
-				}
-			}
-
-			for j := range attrs {
-				if attrs[j] < stats.Min[j] {
-					stats.Min[j] = attrs[j]
-				}
-			}
-		}
-
-		// histogram
-		{
-			for i, a := range attrs {
-				if stats.Histogram[i] == nil {
-					stats.Histogram[i] = map[int]int{}
-				}
-				stats.Histogram[i][a]++
-			}
-		}
-
-		// unique
-		{
-			attrKey := ""
-			for i := range attrs {
-				attrKey += fmt.Sprintf("%v|", attrs[i])
-			}
-
-			unique[attrKey]++
-
-		}
-
-		res[id] = domination.DataRow{
-			ID:    id,
-			Name:  row[1],
-			Attrs: attrs,
-		}
+	strictness, err := domination.ParseStrictness(a.Strictness)
+	if err != nil {
+		panic(err)
 	}
 
-	dataPoints := []domination.DataPoint{}
-
-	fmt.Println(len(unique))
-
-	for k, v := range unique {
-
-		attrs := strings.Split(strings.TrimRight(k, "|"), "|")
-
-		a := []int{}
-		for i := range attrs {
-			iv, _ := strconv.Atoi(attrs[i])
-			a = append(a, iv)
-		}
+	// the generated files have no header row, every line
+	// holds the id followed by the attributes
+	columns := domination.ColumnMapping{
+		Delimiter: "\t",
+		ID:        domination.ColumnIndex(0),
+	}
+	for i := 1; i <= a.DatasetDimensions; i++ {
+		columns.Attrs = append(columns.Attrs, domination.ColumnIndex(i))
+	}
 
-		dataPoints = append(dataPoints, domination.DataPoint{
-			Count: v,
-			Attrs: a,
-		})
+	syntheticReader := &domination.CSVDatasetReader{
+		Columns:    columns,
+		Strictness: strictness,
+		Directions: ds.Directions,
 	}
 
-	stats.Count = len(res)
-	return res, stats, dataPoints
+	err = ds.Calc(syntheticReader, datasetFilename, outputPath, a.Approximate, a.GridSize)
+	if err != nil {
+		panic(err)
+	}
 }
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ngeorgiadis/community-discovery/cmd/datasetGenerator/config"
+	"github.com/ngeorgiadis/community-discovery/internal/generator"
+)
+
+// TestSameSeed checks that a seed reproduces its dataset byte for byte,
+// under the same name
+func TestSameSeed(t *testing.T) {
+	for _, datasetType := range []string{generator.Uniform, generator.Correlated, generator.AntiCorrelated, generator.Clustered} {
+		defaults := generator.DefaultParams(datasetType, 3)
+		a := &config.AppConfig{
+			DatasetType:       datasetType,
+			DatasetSize:       500,
+			DatasetDimensions: 3,
+			ValueRange:        defaults.ValueRange,
+			Correlation:       defaults.Correlation,
+			Clusters:          defaults.Clusters,
+			Spread:            defaults.Spread,
+		}
+
+		generate := func(seed int64) []byte {
+			var buf bytes.Buffer
+			if err := writeDataset(&buf, a, seed); err != nil {
+				t.Fatal(err)
+			}
+			return buf.Bytes()
+		}
+
+		first := generate(42)
+		if !bytes.Equal(first, generate(42)) {
+			t.Errorf("%v: seed 42 generated two different datasets", datasetType)
+		}
+		if bytes.Equal(first, generate(43)) {
+			t.Errorf("%v: seeds 42 and 43 generated the same dataset", datasetType)
+		}
+		if n := bytes.Count(first, []byte("\n")); n != a.DatasetSize {
+			t.Errorf("%v: got %v lines, want %v", datasetType, n, a.DatasetSize)
+		}
+
+		if got, want := datasetName(a, 42), datasetType+"_500x3_seed42"; got != want {
+			t.Errorf("got dataset name %v, want %v", got, want)
+		}
+	}
+}
@@ -2,17 +2,31 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+
+	"github.com/ngeorgiadis/community-discovery/internal/domination"
+	"github.com/ngeorgiadis/community-discovery/internal/settings"
 )
 
 type AppConfig struct {
-	NodesCSVFile   string `json:"nodesCSVFile"`
-	EdgesCSVFile   string `json:"edgesCSVFile"`
-	BaseOutputPath string `json:"baseOutputPath"`
-	GridSize       []int  `json:"gridSize"`
+	NodesCSVFile   string                    `json:"nodesCSVFile"`
+	EdgesCSVFile   string                    `json:"edgesCSVFile"`
+	BaseOutputPath string                    `json:"baseOutputPath"`
+	GridSize       domination.GridSize       `json:"gridSize"`
+	GridMode       string                    `json:"gridMode"`
+	GridSample     int                       `json:"gridSample"`
+	Workers        int                       `json:"workers"`
+	Strictness     string                    `json:"strictness"`
+	Mode           string                    `json:"mode"`
+	Streaming      bool                      `json:"streaming"`
+	Directions     []string                  `json:"directions"`
+	Columns        *domination.ColumnMapping `json:"columns"`
 }
 
-func New(configFile string) (*AppConfig, error) {
+// New reads and validates the settings in configFile for a command
+// whose default columns hold dimensions attributes
+func New(configFile string, dimensions int) (*AppConfig, error) {
 
 	b, err := ioutil.ReadFile(configFile)
 	if err != nil {
@@ -25,5 +39,37 @@ func New(configFile string) (*AppConfig, error) {
 		return nil, err
 	}
 
+	err = c.Validate(dimensions)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", configFile, err)
+	}
+
 	return &c, nil
 }
+
+// Validate checks the settings up front and returns every problem
+// found at once. dimensions is the number of attributes of the default
+// columns of the command, the columns setting overrides it.
+func (c *AppConfig) Validate(dimensions int) error {
+	p := &settings.Problems{}
+
+	if c.Columns != nil {
+		dimensions = len(c.Columns.Attrs)
+		p.Positive("columns.attrs length", dimensions)
+	}
+
+	p.GridSize("gridSize", c.GridSize, dimensions)
+	p.Directions("directions", c.Directions, dimensions)
+
+	p.OneOf("mode", c.Mode, "score", "skyline")
+
+	_, err := domination.ParseGridMode(c.GridMode)
+	p.Check("gridMode", err)
+	_, err = domination.ParseStrictness(c.Strictness)
+	p.Check("strictness", err)
+
+	p.InputFile("nodesCSVFile", c.NodesCSVFile, false)
+	p.OutputDir("baseOutputPath", c.BaseOutputPath)
+
+	return p.Err()
+}
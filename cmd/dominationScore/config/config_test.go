@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSettings(t *testing.T, dir string, content string) string {
+	t.Helper()
+
+	filename := filepath.Join(dir, "settings.json")
+	if err := os.WriteFile(filename, []byte(content), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	nodes := filepath.Join(dir, "nodes.txt")
+	if err := os.WriteFile(nodes, []byte("1\t1\t2\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	// the 2 dimensional settings of cmd/example
+	example := writeSettings(t, dir, `{"nodesCSVFile":`+quote(nodes)+`,"baseOutputPath":".","gridSize":[10,10],"directions":["max","min"]}`)
+	if _, err := New(example, 2); err != nil {
+		t.Errorf("example settings: %v", err)
+	}
+
+	_, err := New(example, 4)
+	if err == nil || !strings.Contains(err.Error(), "gridSize has 2 entries for 4 dimensions") {
+		t.Errorf("example settings for 4 dimensions: got %v", err)
+	}
+
+	columns := writeSettings(t, dir, `{"nodesCSVFile":`+quote(nodes)+`,"gridSize":[10,10,10],"columns":{"id":0,"attrs":[1,2,3]}}`)
+	if _, err := New(columns, 2); err != nil {
+		t.Errorf("settings with columns: %v", err)
+	}
+
+	for mode, valid := range map[string]bool{"skyline": true, "score": true, "skylne": false} {
+		settings := writeSettings(t, dir, `{"nodesCSVFile":`+quote(nodes)+`,"mode":"`+mode+`"}`)
+		if _, err := New(settings, 2); (err == nil) != valid {
+			t.Errorf("mode %v: got %v", mode, err)
+		}
+	}
+}
+
+func quote(s string) string {
+	return `"` + filepath.ToSlash(s) + `"`
+}
@@ -11,7 +11,8 @@ import (
 
 func main() {
 
-	a, err := config.New("settings.json")
+	// the AMiner nodes file has 4 attributes
+	a, err := config.New("settings.json", 4)
 	if err != nil {
 		panic(err)
 	}
@@ -25,9 +26,40 @@ func main() {
 	}
 
 	ds := domination.New()
+	if a.Workers > 0 {
+		ds.Workers = a.Workers
+	}
+	ds.GridSample = a.GridSample
+
+	ds.Directions, err = domination.ParseDirections(a.Directions)
+	if err != nil {
+		panic(err)
+	}
+
+	ds.GridMode, err = domination.ParseGridMode(a.GridMode)
+	if err != nil {
+		panic(err)
+	}
 
 	dsFilePath := path.Join(outputBasePath, "domination.txt")
-	defaultReader := &domination.DefaultDatasetReader{}
+	strictness, err := domination.ParseStrictness(a.Strictness)
+	if err != nil {
+		panic(err)
+	}
+
+	columns, err := domination.AminerColumns(4)
+	if err != nil {
+		panic(err)
+	}
+	if a.Columns != nil {
+		columns = *a.Columns
+	}
+
+	defaultReader := &domination.CSVDatasetReader{
+		Columns:    columns,
+		Strictness: strictness,
+		Directions: ds.Directions,
+	}
 
 	// max 572, 15757, 60, 8308
 	// gridSize := []int{25, 25, 25, 25}
@@ -35,5 +67,18 @@ func main() {
 	// 	10, 10, 10, 10,
 	// }
 
-	ds.Calc(defaultReader, a.NodesCSVFile, dsFilePath, true, a.GridSize)
+	switch a.Mode {
+	case "skyline":
+		skylineFilePath := path.Join(outputBasePath, "skyline.txt")
+		err = ds.CalcSkyline(defaultReader, a.NodesCSVFile, skylineFilePath, a.GridSize)
+	default:
+		if a.Streaming {
+			err = ds.CalcStream(defaultReader, a.NodesCSVFile, dsFilePath, true, a.GridSize)
+			break
+		}
+		err = ds.Calc(defaultReader, a.NodesCSVFile, dsFilePath, true, a.GridSize)
+	}
+	if err != nil {
+		panic(err)
+	}
 }
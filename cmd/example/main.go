@@ -1,26 +1,18 @@
 package main
 
 import (
-	"encoding/csv"
-	"fmt"
-	"log"
-	"math"
 	"os"
 	"path"
-	"strconv"
 	"strings"
 
 	"github.com/ngeorgiadis/community-discovery/cmd/dominationScore/config"
 	"github.com/ngeorgiadis/community-discovery/internal/domination"
 )
 
-type ExampleDatasetReader struct {
-	Dimensions int
-}
-
 func main() {
 
-	a, err := config.New("settings.json")
+	// the example nodes file has 2 attributes, see the columns below
+	a, err := config.New("settings.json", 2)
 	if err != nil {
 		panic(err)
 	}
@@ -34,123 +26,49 @@ func main() {
 	// }
 
 	ds := domination.New()
+	if a.Workers > 0 {
+		ds.Workers = a.Workers
+	}
+	ds.GridSample = a.GridSample
 
-	dsFilePath := path.Join(a.BaseOutputPath, "domination.txt")
-	reader := &ExampleDatasetReader{Dimensions: 2}
-	ds.Calc(reader, a.NodesCSVFile, dsFilePath, false, a.GridSize)
-
-	b, _ := os.ReadFile(dsFilePath)
-
-	os.WriteFile("domination.txt", []byte(strings.TrimSpace(strings.ReplaceAll(string(b), "id	dom", ""))), 0777)
-
-}
-
-func (edr *ExampleDatasetReader) ReadDataset(filename string) (map[int]domination.DataRow, *domination.DataStats, []domination.DataPoint) {
-
-	f, _ := os.Open(filename)
-	r := csv.NewReader(f)
-	r.Comma = '\t'
-
-	fmt.Println("start")
-	fmt.Println("reading...")
-
-	recs, err := r.ReadAll()
+	ds.Directions, err = domination.ParseDirections(a.Directions)
 	if err != nil {
-		log.Fatal(err)
+		panic(err)
 	}
 
-	res := map[int]domination.DataRow{}
-	stats := &domination.DataStats{
-		Max:       make([]int, edr.Dimensions),
-		Min:       make([]int, edr.Dimensions),
-		Histogram: make([]map[int]int, edr.Dimensions),
-		Count:     0,
+	ds.GridMode, err = domination.ParseGridMode(a.GridMode)
+	if err != nil {
+		panic(err)
 	}
 
-	for i := range stats.Max {
-		stats.Max[i] = math.MinInt64
-		stats.Min[i] = math.MaxInt64
+	dsFilePath := path.Join(a.BaseOutputPath, "domination.txt")
+	strictness, err := domination.ParseStrictness(a.Strictness)
+	if err != nil {
+		panic(err)
 	}
 
-	unique := map[string]int{}
-
-	for _, row := range recs {
-		// uncomment if the csv has header row
-		// if i == 0 {
-		// 	continue
-		// }
-
-		attrs := make([]int, edr.Dimensions)
-
-		id, _ := strconv.Atoi(row[0])
-		v1, _ := strconv.Atoi(row[1])
-		v2, _ := strconv.Atoi(row[2])
-		attrs[0] = v1
-		attrs[1] = v2
-
-		// stats max
-		{
-
-			for j := range attrs {
-				if attrs[j] > stats.Max[j] {
-					stats.Max[j] = attrs[j]
-				}
-			}
-
-			for j := range attrs {
-				if attrs[j] < stats.Min[j] {
-					stats.Min[j] = attrs[j]
-				}
-			}
-		}
-
-		// histogram
-		{
-			for i, a := range attrs {
-				if stats.Histogram[i] == nil {
-					stats.Histogram[i] = map[int]int{}
-				}
-				stats.Histogram[i][a]++
-			}
-		}
-
-		// unique
-		{
-			attrKey := ""
-			for i := range attrs {
-				attrKey += fmt.Sprintf("%v|", attrs[i])
-			}
-
-			unique[attrKey]++
-		}
-
-		res[id] = domination.DataRow{
-			ID:    id,
-			Name:  fmt.Sprintf("n%v", id),
-			Attrs: attrs,
-		}
-
+	// the example nodes file has no header row, every
+	// line holds the id followed by two attributes
+	columns := domination.ColumnMapping{
+		Delimiter: "\t",
+		ID:        domination.ColumnIndex(0),
+		Attrs: []domination.Column{
+			domination.ColumnIndex(1),
+			domination.ColumnIndex(2),
+		},
+	}
+	if a.Columns != nil {
+		columns = *a.Columns
 	}
 
-	dataPoints := []domination.DataPoint{}
-
-	for k, v := range unique {
-
-		attrs := strings.Split(strings.TrimRight(k, "|"), "|")
-
-		a := []int{}
-		for i := range attrs {
-			iv, _ := strconv.Atoi(attrs[i])
-			a = append(a, iv)
-		}
-
-		dataPoints = append(dataPoints, domination.DataPoint{
-			Count: v,
-			Attrs: a,
-		})
+	reader := &domination.CSVDatasetReader{Columns: columns, Strictness: strictness, Directions: ds.Directions}
+	err = ds.Calc(reader, a.NodesCSVFile, dsFilePath, false, a.GridSize)
+	if err != nil {
+		panic(err)
 	}
 
-	stats.Count = len(res)
-	return res, stats, dataPoints
+	b, _ := os.ReadFile(dsFilePath)
+
+	os.WriteFile("domination.txt", []byte(strings.TrimSpace(strings.ReplaceAll(string(b), "id	dom", ""))), 0777)
 
 }
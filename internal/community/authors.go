@@ -0,0 +1,87 @@
+package community
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Author holds the fields of an author of the AMiner-Author file
+type Author struct {
+	ID   int
+	Name string
+
+	// PC is the paper count, CN the citation number,
+	// HI the h-index and PI the P-index
+	PC int
+	CN int
+	HI int
+	PI float64
+}
+
+// ReadAuthors reads the authors of the AMiner-Author format, where
+// every author is a block of "#index", "#n", "#pc", "#cn", "#hi" and
+// "#pi" lines, among others, ending with an empty line
+func ReadAuthors(r io.Reader) (map[int]*Author, error) {
+	res := map[int]*Author{}
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var a *Author
+	line := 0
+	for s.Scan() {
+		line++
+		ln := s.Text()
+
+		var err error
+		switch {
+		case strings.HasPrefix(ln, "#index "):
+			a = &Author{}
+			a.ID, err = strconv.Atoi(strings.TrimPrefix(ln, "#index "))
+			res[a.ID] = a
+		case a == nil:
+			// lines before the first author
+		case strings.HasPrefix(ln, "#n "):
+			a.Name = strings.TrimPrefix(ln, "#n ")
+		case strings.HasPrefix(ln, "#pc "):
+			a.PC, err = strconv.Atoi(strings.TrimPrefix(ln, "#pc "))
+		case strings.HasPrefix(ln, "#cn "):
+			a.CN, err = strconv.Atoi(strings.TrimPrefix(ln, "#cn "))
+		case strings.HasPrefix(ln, "#hi "):
+			a.HI, err = strconv.Atoi(strings.TrimPrefix(ln, "#hi "))
+		case strings.HasPrefix(ln, "#pi "):
+			a.PI, err = strconv.ParseFloat(strings.TrimPrefix(ln, "#pi "), 64)
+		case ln == "":
+			a = nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", line, err)
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// LoadAuthors reads the authors in filename, see ReadAuthors
+func LoadAuthors(filename string) (map[int]*Author, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	authors, err := ReadAuthors(f)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", filename, err)
+	}
+	return authors, nil
+}
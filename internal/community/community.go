@@ -0,0 +1,204 @@
+package community
+
+import (
+	"sort"
+	"time"
+)
+
+// Community is the max k-core of the egonet of an initial node
+type Community struct {
+	// Init is the node id the egonet was taken around
+	Init int
+
+	// Graph is the max k-core, or the egonet with WholeEgonet,
+	// with the vertices in the order of the egonet
+	Graph *Graph
+	Stats Stats
+
+	// EgoTime and CoreTime are the time taken to find
+	// the egonet and the max k-core
+	EgoTime  time.Duration
+	CoreTime time.Duration
+}
+
+// Searcher finds the communities of a graph with the k-core search of
+// the community discovery scripts: take the egonet of a node, find the
+// max k-core of the egonet and rank it by the domination scores of its
+// nodes.
+type Searcher struct {
+	Graph *Graph
+
+	// Dom holds the domination score of every node
+	// and MaxDom the highest one
+	Dom    map[int]int
+	MaxDom int
+
+	// Hops is the radius of the egonets
+	Hops int
+
+	// PositiveDom drops the nodes with a domination score of 0
+	// from the egonets before the core decomposition
+	PositiveDom bool
+
+	// WholeEgonet makes every community the whole egonet instead of
+	// its max k-core, with the stats taken for a core number of 1
+	// like the api does
+	WholeEgonet bool
+}
+
+// NewSearcher returns a Searcher for the graph g and the domination
+// scores dom, taking egonets of the given radius
+func NewSearcher(g *Graph, dom map[int]int, hops int) *Searcher {
+	maxDom := 0
+	for _, d := range dom {
+		if d > maxDom {
+			maxDom = d
+		}
+	}
+
+	return &Searcher{
+		Graph:  g,
+		Dom:    dom,
+		MaxDom: maxDom,
+		Hops:   hops,
+	}
+}
+
+// Rank returns the node ids of dom by descending domination score and,
+// for equal scores, by descending id, the order read_dom gives them
+func Rank(dom map[int]int) []int {
+	ids := make([]int, 0, len(dom))
+	for id := range dom {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := ids[i], ids[j]
+		if dom[a] != dom[b] {
+			return dom[a] > dom[b]
+		}
+		return a > b
+	})
+
+	return ids
+}
+
+// Egonet returns the egonet of the node init, without the
+// nodes that exclude returns true for, when it is not nil
+func (s *Searcher) Egonet(init int, exclude func(id int) bool) (*Graph, error) {
+	e, err := s.Graph.Egonet(init, s.Hops)
+	if err != nil {
+		return nil, err
+	}
+
+	if exclude == nil && !s.PositiveDom {
+		return e, nil
+	}
+
+	return e.Filter(func(id int) bool {
+		if exclude != nil && exclude(id) {
+			return false
+		}
+		return !s.PositiveDom || s.Dom[id] > 0
+	}), nil
+}
+
+// Community returns the community of the node init, the max k-core of
+// its egonet. It returns nil when the egonet has no nodes left after
+// dropping the ones with a domination score of 0.
+func (s *Searcher) Community(init int) (*Community, error) {
+	t1 := time.Now()
+	e, err := s.Egonet(init, nil)
+	if err != nil {
+		return nil, err
+	}
+	egoTime := time.Since(t1)
+
+	if e.Order() == 0 {
+		return nil, nil
+	}
+
+	c := s.community(init, e)
+	c.EgoTime = egoTime
+	return c, nil
+}
+
+func (s *Searcher) community(init int, egonet *Graph) *Community {
+	if s.WholeEgonet {
+		return &Community{
+			Init:  init,
+			Graph: egonet,
+			Stats: NewStats(egonet, s.Dom, s.MaxDom, 1),
+		}
+	}
+
+	t1 := time.Now()
+	core, k := egonet.MaxKCore()
+	coreTime := time.Since(t1)
+
+	return &Community{
+		Init:     init,
+		Graph:    core,
+		Stats:    NewStats(core, s.Dom, s.MaxDom, k),
+		CoreTime: coreTime,
+	}
+}
+
+// Partition splits the graph into non overlapping communities. Every
+// community leaves out the nodes of the communities found before it.
+type Partition struct {
+	s       *Searcher
+	visited map[int]bool
+
+	// EgoTime and CoreTime add up the time taken to find the egonets
+	// and the max k-cores, including the egonets left without a
+	// community
+	EgoTime  time.Duration
+	CoreTime time.Duration
+}
+
+// Partition returns an empty Partition of the graph of s
+func (s *Searcher) Partition() *Partition {
+	return &Partition{
+		s:       s,
+		visited: map[int]bool{},
+	}
+}
+
+// Visited reports whether the node id is in a community already
+func (p *Partition) Visited(id int) bool {
+	return p.visited[id]
+}
+
+// Community returns the community of the node init among the nodes not
+// in a community yet and adds it to the partition. The egonet is taken
+// on the whole graph and the visited nodes are dropped from it after.
+// It returns nil when init is in a community already or when at most
+// one node of its egonet is left.
+func (p *Partition) Community(init int) (*Community, error) {
+	if p.visited[init] {
+		return nil, nil
+	}
+
+	t1 := time.Now()
+	e, err := p.s.Egonet(init, p.Visited)
+	if err != nil {
+		return nil, err
+	}
+	egoTime := time.Since(t1)
+	p.EgoTime += egoTime
+
+	if e.Order() <= 1 {
+		return nil, nil
+	}
+
+	c := p.s.community(init, e)
+	c.EgoTime = egoTime
+	p.CoreTime += c.CoreTime
+
+	for _, id := range c.Graph.IDs() {
+		p.visited[id] = true
+	}
+
+	return c, nil
+}
@@ -0,0 +1,130 @@
+package community
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// the graph of TestMaxKCore with a domination score for every node
+func testSearcher(hops int) *Searcher {
+	g := graphOf(
+		[2]int{5, 6}, [2]int{5, 7}, [2]int{5, 8}, [2]int{6, 7}, [2]int{6, 8}, [2]int{7, 8},
+		[2]int{8, 9}, [2]int{9, 10},
+		[2]int{1, 2}, [2]int{2, 3}, [2]int{3, 1}, [2]int{3, 5},
+	)
+
+	dom := map[int]int{1: 9, 2: 0, 3: 1, 5: 10, 6: 8, 7: 6, 8: 4, 9: 0, 10: 0}
+	return NewSearcher(g, dom, hops)
+}
+
+func TestRank(t *testing.T) {
+	got := Rank(map[int]int{1: 3, 2: 5, 3: 3, 4: 0, 5: 3})
+	if fmt.Sprint(got) != "[2 5 3 1 4]" {
+		t.Errorf("got %v, want [2 5 3 1 4]", got)
+	}
+}
+
+func TestCommunity(t *testing.T) {
+	s := testSearcher(2)
+	if s.MaxDom != 10 {
+		t.Fatalf("got max dom %v, want 10", s.MaxDom)
+	}
+
+	c, err := s.Community(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the egonet order is 8 5 6 7 9 3 10
+	if fmt.Sprint(c.Graph.IDs()) != "[8 5 6 7]" {
+		t.Errorf("got community %v, want [8 5 6 7]", c.Graph.IDs())
+	}
+
+	stddev := math.Sqrt((0.0 + 4 + 16 + 36) / 4)
+	want := Stats{
+		NumberOfNodes: 4,
+		RatioMaxKCore: 0.75,
+		MaxKCore:      3,
+		MaxStddev:     stddev,
+		E2:            3 * 0.75 / stddev,
+		E4:            3.75 / stddev,
+	}
+	if c.Stats != want {
+		t.Errorf("got stats %+v, want %+v", c.Stats, want)
+	}
+
+	s.PositiveDom = true
+	e, err := s.Egonet(3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(e.IDs()) != "[3 1 5 6 7 8]" {
+		t.Errorf("got egonet %v, want [3 1 5 6 7 8]", e.IDs())
+	}
+
+	s.Hops = 1
+	c, err = s.Community(10)
+	if err != nil || c != nil {
+		t.Errorf("got community %v and error %v, want neither", c, err)
+	}
+
+	if _, err := s.Community(4); err == nil {
+		t.Error("expected an error for a missing node")
+	}
+}
+
+func TestPartition(t *testing.T) {
+	s := testSearcher(1)
+	p := s.Partition()
+
+	got := []string{}
+	for _, id := range Rank(s.Dom) {
+		c, err := p.Community(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c != nil {
+			got = append(got, fmt.Sprintf("%v:%v", c.Init, c.Graph.IDs()))
+		}
+	}
+
+	// 5 takes the clique, 1 the triangle and 10, the first of
+	// the nodes with a score of 0, the tail
+	want := "[5:[5 6 7 8] 1:[1 2 3] 10:[10 9]]"
+	if fmt.Sprint(got) != want {
+		t.Errorf("got communities %v, want %v", got, want)
+	}
+
+	if !p.Visited(10) || p.Visited(4) {
+		t.Error("wrong visited nodes")
+	}
+}
+
+func TestWholeEgonet(t *testing.T) {
+	s := testSearcher(1)
+	s.WholeEgonet = true
+
+	c, err := s.Community(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(c.Graph.IDs()) != "[8 5 6 7 9]" || c.Stats.MaxKCore != 1 || c.Stats.RatioMaxKCore != 0.2 {
+		t.Errorf("got community %v with stats %+v", c.Graph.IDs(), c.Stats)
+	}
+}
+
+func TestGraphMeasures(t *testing.T) {
+	// a triangle 1, 2, 3 with 4 hanging from 3
+	g := graphOf([2]int{1, 2}, [2]int{2, 3}, [2]int{3, 1}, [2]int{3, 4})
+
+	if got := fmt.Sprint(g.LocalClustering()); got != fmt.Sprint([]float64{1, 1, 1.0 / 3, 0}) {
+		t.Errorf("got local clustering %v", got)
+	}
+	if got := g.AvgClustering(); math.Abs(got-7.0/12) > 1e-12 {
+		t.Errorf("got average clustering %v, want 7/12", got)
+	}
+	if g.Density() != 4.0/6 || g.AvgDegree() != 2 {
+		t.Errorf("got density %v and average degree %v", g.Density(), g.AvgDegree())
+	}
+}
@@ -0,0 +1,180 @@
+package community
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ReadEdges reads a graph from an edge list with one edge per line.
+// Lines are either in the AMiner-Coauthor format, "#src\tdst\tweight"
+// with the first author id prefixed by '#', or plain "src dst [weight]"
+// lines separated by tabs, spaces or commas. Edges without a weight get
+// a weight of 1. Empty lines and comment lines, lines starting with '%'
+// or with a '#' that is not followed by an author id, are skipped. The
+// vertices of the graph are the authors found in the edges.
+func ReadEdges(r io.Reader) (*Graph, error) {
+	b := NewBuilder()
+	if err := b.ReadEdges(r); err != nil {
+		return nil, err
+	}
+	return b.Build(), nil
+}
+
+// ReadEdges adds the edges of the edge list read from r, see ReadEdges.
+// Nodes added to b beforehand stay in the graph without edges.
+func (b *Builder) ReadEdges(r io.Reader) error {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	line := 0
+	for s.Scan() {
+		line++
+
+		from, to, weight, ok, err := parseEdge(s.Text())
+		if err != nil {
+			return fmt.Errorf("line %v: %w", line, err)
+		}
+		if ok {
+			b.AddWeightedEdge(from, to, weight)
+		}
+	}
+
+	return s.Err()
+}
+
+// LoadEdges reads the graph of the edge list in filename, see ReadEdges
+func LoadEdges(filename string) (*Graph, error) {
+	b := NewBuilder()
+	if err := b.LoadEdges(filename); err != nil {
+		return nil, err
+	}
+	return b.Build(), nil
+}
+
+// LoadEdges adds the edges of the edge list in filename, see ReadEdges
+func (b *Builder) LoadEdges(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = b.ReadEdges(f)
+	if err != nil {
+		return fmt.Errorf("%v: %w", filename, err)
+	}
+	return nil
+}
+
+// parseEdge parses a line of an edge list. It returns false
+// for the empty lines and the comment lines.
+func parseEdge(ln string) (int, int, float64, bool, error) {
+	ln = strings.TrimSpace(ln)
+	if ln == "" || ln[0] == '%' {
+		return 0, 0, 0, false, nil
+	}
+
+	if ln[0] == '#' {
+		// AMiner edges have the first author id right after the '#'
+		if len(ln) == 1 || !unicode.IsDigit(rune(ln[1])) {
+			return 0, 0, 0, false, nil
+		}
+		ln = ln[1:]
+	}
+
+	fields := strings.FieldsFunc(ln, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	if len(fields) != 2 && len(fields) != 3 {
+		return 0, 0, 0, false, fmt.Errorf("expected 2 or 3 fields, got %v", len(fields))
+	}
+
+	from, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, 0, false, err
+	}
+
+	to, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, 0, false, err
+	}
+
+	weight := 1.0
+	if len(fields) == 3 {
+		weight, err = strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+	}
+
+	return from, to, weight, true, nil
+}
+
+// ReadScores reads the domination scores written by the domination
+// package, one tab separated id and score per line, skipping the
+// "id\tdom" header
+func ReadScores(r io.Reader) (map[int]int, error) {
+	dom := map[int]int{}
+
+	s := bufio.NewScanner(r)
+
+	line := 0
+	for s.Scan() {
+		line++
+
+		p := strings.Split(s.Text(), "\t")
+		if len(p) != 2 || (line == 1 && p[0] == "id") {
+			continue
+		}
+
+		id, err := strconv.Atoi(p[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", line, err)
+		}
+
+		d, err := strconv.Atoi(p[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", line, err)
+		}
+
+		dom[id] = d
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return dom, nil
+}
+
+// LoadScores reads the domination scores in filename, see ReadScores
+func LoadScores(filename string) (map[int]int, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dom, err := ReadScores(f)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", filename, err)
+	}
+	return dom, nil
+}
+
+// Unscored returns the node ids of g that have no domination score
+// in dom, in vertex order. Their score counts as 0 in the search.
+func Unscored(g *Graph, dom map[int]int) []int {
+	res := []int{}
+	for _, id := range g.IDs() {
+		if _, ok := dom[id]; !ok {
+			res = append(res, id)
+		}
+	}
+	return res
+}
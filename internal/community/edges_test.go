@@ -0,0 +1,102 @@
+package community
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadEdges(t *testing.T) {
+	input := "# coauthor pairs\n" +
+		"#7\t3\t2\n" +
+		"#3\t7\t5\n" +
+		"#3\t3\t1\n" +
+		"\n" +
+		"% plain edges\n" +
+		"3 12\n" +
+		"12,20,0.5\n"
+
+	g, err := ReadEdges(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(g.IDs()) != "[3 7 12 20]" || g.Size() != 3 {
+		t.Fatalf("got nodes %v and %v edges", g.IDs(), g.Size())
+	}
+
+	v, _ := g.Vertex(3)
+	if fmt.Sprint(g.Neighbors(v), g.Weights(v)) != "[1 2] [5 1]" {
+		t.Errorf("got neighbours %v and weights %v of node 3", g.Neighbors(v), g.Weights(v))
+	}
+
+	v, _ = g.Vertex(20)
+	if fmt.Sprint(g.Weights(v)) != "[0.5]" {
+		t.Errorf("got weights %v of node 20", g.Weights(v))
+	}
+
+	// the weights follow the vertices into subgraphs
+	e, err := g.Egonet(12, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(e.IDs(), e.Neighbors(0), e.Weights(0)) != "[12 3 20] [1 2] [1 0.5]" {
+		t.Errorf("got egonet %v, neighbours %v and weights %v", e.IDs(), e.Neighbors(0), e.Weights(0))
+	}
+
+	for _, bad := range []string{"1\n", "#1\t2\t3\t4\n", "a b\n", "1 2 x\n"} {
+		if _, err := ReadEdges(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestLoadScores(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "domination.txt")
+	err := os.WriteFile(filename, []byte("id\tdom\n3\t10\n7\t0\n20\t4\n99\t1\n"), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dom, err := LoadScores(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dom) != 4 || dom[3] != 10 || dom[20] != 4 {
+		t.Errorf("got scores %v", dom)
+	}
+
+	g := graphOf([2]int{3, 7}, [2]int{7, 12}, [2]int{12, 20})
+	if got := Unscored(g, dom); fmt.Sprint(got) != "[12]" {
+		t.Errorf("got unscored nodes %v, want [12]", got)
+	}
+}
+
+func TestReadAuthors(t *testing.T) {
+	input := "#index 1\n#n Jane Doe\n#a MIT\n#pc 3\n#cn 40\n#hi 2\n#pi 1.5\n#upi 0.4\n#t data\n\n" +
+		"#index 2\n#n John Roe\n#pc 1\n#cn 0\n#hi 0\n#pi 0.0000\n"
+
+	authors, err := ReadAuthors(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int]Author{
+		1: {ID: 1, Name: "Jane Doe", PC: 3, CN: 40, HI: 2, PI: 1.5},
+		2: {ID: 2, Name: "John Roe", PC: 1},
+	}
+	if len(authors) != len(want) {
+		t.Fatalf("got %v authors, want %v", len(authors), len(want))
+	}
+	for id, a := range want {
+		if authors[id] == nil || *authors[id] != a {
+			t.Errorf("got author %+v, want %+v", authors[id], a)
+		}
+	}
+
+	if _, err := ReadAuthors(strings.NewReader("#index 1\n#pc x\n")); err == nil {
+		t.Error("expected an error for a malformed paper count")
+	}
+}
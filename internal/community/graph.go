@@ -0,0 +1,370 @@
+package community
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Graph is an undirected simple graph stored in compressed sparse row
+// form. Vertices are numbered from 0 and every vertex carries the id of
+// the node it stands for, an author id in the AMiner datasets.
+type Graph struct {
+	// offsets[v] to offsets[v+1] is the range of adj holding
+	// the neighbours of v and of weights holding the weights
+	// of the edges to them
+	offsets []int
+	adj     []int
+	weights []float64
+
+	ids   []int
+	index map[int]int
+}
+
+// Order returns the number of vertices
+func (g *Graph) Order() int {
+	return len(g.ids)
+}
+
+// Size returns the number of edges
+func (g *Graph) Size() int {
+	return len(g.adj) / 2
+}
+
+// ID returns the node id of vertex v
+func (g *Graph) ID(v int) int {
+	return g.ids[v]
+}
+
+// IDs returns the node ids of all vertices, in vertex order
+func (g *Graph) IDs() []int {
+	return g.ids
+}
+
+// Vertex returns the vertex of the node id and
+// false when the node is not in the graph
+func (g *Graph) Vertex(id int) (int, bool) {
+	v, ok := g.index[id]
+	return v, ok
+}
+
+// Neighbors returns the neighbours of v in increasing vertex order.
+// The slice is shared with the graph and should not be modified.
+func (g *Graph) Neighbors(v int) []int {
+	return g.adj[g.offsets[v]:g.offsets[v+1]]
+}
+
+// Weights returns the weights of the edges of v, in the order of
+// Neighbors. The slice is shared with the graph and should not be
+// modified.
+func (g *Graph) Weights(v int) []float64 {
+	return g.weights[g.offsets[v]:g.offsets[v+1]]
+}
+
+// Degree returns the number of neighbours of v
+func (g *Graph) Degree(v int) int {
+	return g.offsets[v+1] - g.offsets[v]
+}
+
+// Neighborhood returns the vertices within hops of v in breadth first
+// order, v first, visiting the neighbours of every vertex in increasing
+// vertex order. This is the order Graphs.jl gives to the vertices of an
+// egonet.
+func (g *Graph) Neighborhood(v int, hops int) []int {
+	if hops < 0 {
+		return []int{}
+	}
+
+	seen := map[int]bool{v: true}
+	queue := []int{v}
+	dist := []int{0}
+
+	for i := 0; i < len(queue); i++ {
+		if dist[i] >= hops {
+			continue
+		}
+
+		for _, u := range g.Neighbors(queue[i]) {
+			if !seen[u] {
+				seen[u] = true
+				queue = append(queue, u)
+				dist = append(dist, dist[i]+1)
+			}
+		}
+	}
+
+	return queue
+}
+
+// InducedSubgraph returns the subgraph induced by vertices. Vertex i of
+// the subgraph is vertices[i] of g, so the subgraph keeps the order of
+// vertices rather than the order of g.
+func (g *Graph) InducedSubgraph(vertices []int) *Graph {
+	local := make(map[int]int, len(vertices))
+	for i, v := range vertices {
+		local[v] = i
+	}
+
+	sub := &Graph{
+		offsets: make([]int, 1, len(vertices)+1),
+		ids:     make([]int, len(vertices)),
+		index:   make(map[int]int, len(vertices)),
+	}
+
+	for i, v := range vertices {
+		sub.ids[i] = g.ids[v]
+		sub.index[g.ids[v]] = i
+
+		start := len(sub.adj)
+		weights := g.Weights(v)
+		for k, u := range g.Neighbors(v) {
+			if j, ok := local[u]; ok {
+				sub.adj = append(sub.adj, j)
+				sub.weights = append(sub.weights, weights[k])
+			}
+		}
+		sortEdges(sub.adj[start:], sub.weights[start:])
+
+		sub.offsets = append(sub.offsets, len(sub.adj))
+	}
+
+	return sub
+}
+
+// Egonet returns the subgraph induced by the nodes within hops of the
+// node id
+func (g *Graph) Egonet(id int, hops int) (*Graph, error) {
+	v, ok := g.Vertex(id)
+	if !ok {
+		return nil, fmt.Errorf("node %v not in the graph", id)
+	}
+
+	return g.InducedSubgraph(g.Neighborhood(v, hops)), nil
+}
+
+// Filter returns the subgraph induced by the vertices whose node id
+// keep returns true for, in vertex order
+func (g *Graph) Filter(keep func(id int) bool) *Graph {
+	vertices := []int{}
+	for v, id := range g.ids {
+		if keep(id) {
+			vertices = append(vertices, v)
+		}
+	}
+	return g.InducedSubgraph(vertices)
+}
+
+// CoreNumbers returns the core number of every vertex, the largest k
+// for which the vertex belongs to the k-core of the graph. It uses the
+// O(m) bucket algorithm of Batagelj and Zaversnik.
+func (g *Graph) CoreNumbers() []int {
+	n := g.Order()
+
+	degree := make([]int, n)
+	maxDegree := 0
+	for v := range degree {
+		degree[v] = g.Degree(v)
+		if degree[v] > maxDegree {
+			maxDegree = degree[v]
+		}
+	}
+
+	// bin sort the vertices by degree
+	bin := make([]int, maxDegree+1)
+	for _, d := range degree {
+		bin[d]++
+	}
+	start := 0
+	for d := range bin {
+		bin[d], start = start, start+bin[d]
+	}
+
+	pos := make([]int, n)
+	order := make([]int, n)
+	for v, d := range degree {
+		pos[v] = bin[d]
+		order[pos[v]] = v
+		bin[d]++
+	}
+	for d := maxDegree; d > 0; d-- {
+		bin[d] = bin[d-1]
+	}
+	if len(bin) > 0 {
+		bin[0] = 0
+	}
+
+	for i := 0; i < n; i++ {
+		v := order[i]
+		for _, u := range g.Neighbors(v) {
+			if degree[u] <= degree[v] {
+				continue
+			}
+
+			// move u to the start of its bin and shrink the bin
+			du := degree[u]
+			pu := pos[u]
+			pw := bin[du]
+			w := order[pw]
+			if u != w {
+				order[pu], order[pw] = w, u
+				pos[u], pos[w] = pw, pu
+			}
+			bin[du]++
+			degree[u]--
+		}
+	}
+
+	return degree
+}
+
+// MaxKCore returns the subgraph induced by the vertices with the
+// largest core number k, along with k. The subgraph keeps the vertex
+// order of g.
+func (g *Graph) MaxKCore() (*Graph, int) {
+	core := g.CoreNumbers()
+
+	k := 0
+	for _, c := range core {
+		if c > k {
+			k = c
+		}
+	}
+
+	vertices := []int{}
+	for v, c := range core {
+		if c >= k {
+			vertices = append(vertices, v)
+		}
+	}
+
+	return g.InducedSubgraph(vertices), k
+}
+
+// sortEdges sorts the neighbours adj of a vertex
+// along with the weights of the edges to them
+func sortEdges(adj []int, weights []float64) {
+	sort.Sort(edgeSorter{adj, weights})
+}
+
+type edgeSorter struct {
+	adj     []int
+	weights []float64
+}
+
+func (es edgeSorter) Len() int           { return len(es.adj) }
+func (es edgeSorter) Less(i, j int) bool { return es.adj[i] < es.adj[j] }
+func (es edgeSorter) Swap(i, j int) {
+	es.adj[i], es.adj[j] = es.adj[j], es.adj[i]
+	es.weights[i], es.weights[j] = es.weights[j], es.weights[i]
+}
+
+type edge struct {
+	from, to int
+	weight   float64
+}
+
+// Builder collects the nodes and the edges of a Graph
+type Builder struct {
+	nodes map[int]bool
+	edges []edge
+}
+
+// NewBuilder returns an empty Builder
+func NewBuilder() *Builder {
+	return &Builder{
+		nodes: map[int]bool{},
+	}
+}
+
+// AddNode adds the node id, which is enough
+// for nodes without edges
+func (b *Builder) AddNode(id int) {
+	b.nodes[id] = true
+}
+
+// AddEdge adds an edge of weight 1 between the nodes
+// from and to, adding the nodes too
+func (b *Builder) AddEdge(from, to int) {
+	b.AddWeightedEdge(from, to, 1)
+}
+
+// AddWeightedEdge adds an edge of the given weight between
+// the nodes from and to, adding the nodes too
+func (b *Builder) AddWeightedEdge(from, to int, weight float64) {
+	b.nodes[from] = true
+	b.nodes[to] = true
+	b.edges = append(b.edges, edge{from, to, weight})
+}
+
+// Build returns the graph of the nodes and the edges added so far.
+// Vertices are numbered in increasing node id order, self loops are
+// dropped and repeated edges are kept once, with the largest of their
+// weights.
+func (b *Builder) Build() *Graph {
+	g := &Graph{
+		ids:   make([]int, 0, len(b.nodes)),
+		index: make(map[int]int, len(b.nodes)),
+	}
+
+	for id := range b.nodes {
+		g.ids = append(g.ids, id)
+	}
+	sort.Ints(g.ids)
+	for v, id := range g.ids {
+		g.index[id] = v
+	}
+
+	n := len(g.ids)
+	degree := make([]int, n)
+	for _, e := range b.edges {
+		if e.from != e.to {
+			degree[g.index[e.from]]++
+			degree[g.index[e.to]]++
+		}
+	}
+
+	offsets := make([]int, n+1)
+	for v := 0; v < n; v++ {
+		offsets[v+1] = offsets[v] + degree[v]
+	}
+
+	adj := make([]int, offsets[n])
+	weights := make([]float64, offsets[n])
+	next := make([]int, n)
+	copy(next, offsets[:n])
+	for _, e := range b.edges {
+		if e.from == e.to {
+			continue
+		}
+		u, v := g.index[e.from], g.index[e.to]
+		adj[next[u]], weights[next[u]] = v, e.weight
+		adj[next[v]], weights[next[v]] = u, e.weight
+		next[u]++
+		next[v]++
+	}
+
+	// sort and deduplicate every neighbour list,
+	// compacting adj and weights in place
+	g.offsets = make([]int, 1, n+1)
+	end := 0
+	for v := 0; v < n; v++ {
+		list := adj[offsets[v]:offsets[v+1]]
+		sortEdges(list, weights[offsets[v]:offsets[v+1]])
+
+		for i, u := range list {
+			w := weights[offsets[v]+i]
+			if end > g.offsets[v] && adj[end-1] == u {
+				if w > weights[end-1] {
+					weights[end-1] = w
+				}
+				continue
+			}
+			adj[end], weights[end] = u, w
+			end++
+		}
+		g.offsets = append(g.offsets, end)
+	}
+	g.adj = adj[:end:end]
+	g.weights = weights[:end:end]
+
+	return g
+}
@@ -0,0 +1,155 @@
+package community
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func graphOf(edges ...[2]int) *Graph {
+	b := NewBuilder()
+	for _, e := range edges {
+		b.AddEdge(e[0], e[1])
+	}
+	return b.Build()
+}
+
+// referenceCoreNumbers peels the vertex of the lowest degree
+// until no vertex is left
+func referenceCoreNumbers(g *Graph) []int {
+	n := g.Order()
+	degree := make([]int, n)
+	removed := make([]bool, n)
+	for v := range degree {
+		degree[v] = g.Degree(v)
+	}
+
+	core := make([]int, n)
+	k := 0
+	for left := n; left > 0; left-- {
+		v := -1
+		for u := range degree {
+			if !removed[u] && (v < 0 || degree[u] < degree[v]) {
+				v = u
+			}
+		}
+
+		if degree[v] > k {
+			k = degree[v]
+		}
+		core[v] = k
+		removed[v] = true
+
+		for _, u := range g.Neighbors(v) {
+			if !removed[u] {
+				degree[u]--
+			}
+		}
+	}
+
+	return core
+}
+
+func TestBuild(t *testing.T) {
+	b := NewBuilder()
+	b.AddEdge(30, 10)
+	b.AddEdge(10, 30)
+	b.AddEdge(10, 10)
+	b.AddEdge(20, 30)
+	b.AddNode(40)
+	g := b.Build()
+
+	if g.Order() != 4 || g.Size() != 2 {
+		t.Fatalf("got %v vertices and %v edges, want 4 and 2", g.Order(), g.Size())
+	}
+	if fmt.Sprint(g.IDs()) != "[10 20 30 40]" {
+		t.Errorf("got ids %v", g.IDs())
+	}
+
+	v, ok := g.Vertex(30)
+	if !ok || v != 2 {
+		t.Errorf("got vertex %v for node 30", v)
+	}
+	if fmt.Sprint(g.Neighbors(v)) != "[0 1]" || g.Degree(3) != 0 {
+		t.Errorf("got neighbours %v of node 30", g.Neighbors(v))
+	}
+
+	if _, ok := g.Vertex(50); ok {
+		t.Errorf("found node 50")
+	}
+}
+
+func TestEgonet(t *testing.T) {
+	g := graphOf(
+		[2]int{1, 5}, [2]int{1, 3}, [2]int{5, 2},
+		[2]int{3, 4}, [2]int{4, 6}, [2]int{2, 4},
+	)
+
+	for hops, want := range []string{"[1]", "[1 3 5]", "[1 3 5 4 2]", "[1 3 5 4 2 6]"} {
+		e, err := g.Egonet(1, hops)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(e.IDs()); got != want {
+			t.Errorf("%v hops: got %v, want %v", hops, got, want)
+		}
+	}
+
+	e, _ := g.Egonet(1, 2)
+	if e.Size() != 5 {
+		t.Errorf("got %v edges, want 5", e.Size())
+	}
+
+	// 4 is vertex 3 and 2 is vertex 4 of the egonet
+	if fmt.Sprint(e.Neighbors(3)) != "[1 4]" {
+		t.Errorf("got neighbours %v of node 4", e.Neighbors(3))
+	}
+
+	if _, err := g.Egonet(7, 1); err == nil {
+		t.Error("expected an error for a missing node")
+	}
+}
+
+func TestMaxKCore(t *testing.T) {
+	// a 4-clique on 5, 6, 7, 8 with a tail and a triangle
+	g := graphOf(
+		[2]int{5, 6}, [2]int{5, 7}, [2]int{5, 8}, [2]int{6, 7}, [2]int{6, 8}, [2]int{7, 8},
+		[2]int{8, 9}, [2]int{9, 10},
+		[2]int{1, 2}, [2]int{2, 3}, [2]int{3, 1}, [2]int{3, 5},
+	)
+
+	if got := fmt.Sprint(g.CoreNumbers()); got != "[2 2 2 3 3 3 3 1 1]" {
+		t.Errorf("got core numbers %v", got)
+	}
+
+	core, k := g.MaxKCore()
+	if k != 3 || fmt.Sprint(core.IDs()) != "[5 6 7 8]" || core.Size() != 6 {
+		t.Errorf("got %v-core %v with %v edges", k, core.IDs(), core.Size())
+	}
+
+	core, k = NewBuilder().Build().MaxKCore()
+	if k != 0 || core.Order() != 0 {
+		t.Errorf("got %v-core %v of the empty graph", k, core.IDs())
+	}
+}
+
+func TestCoreNumbersMatchReference(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 50; i++ {
+		n := 1 + r.Intn(60)
+		b := NewBuilder()
+		for v := 0; v < n; v++ {
+			b.AddNode(v)
+		}
+		for e := r.Intn(4 * n); e > 0; e-- {
+			b.AddEdge(r.Intn(n), r.Intn(n))
+		}
+		g := b.Build()
+
+		got, want := fmt.Sprint(g.CoreNumbers()), fmt.Sprint(referenceCoreNumbers(g))
+		if got != want {
+			t.Errorf("got core numbers %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package community
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Row is a line of the results file of the community search
+type Row struct {
+	Init int
+
+	// OriginalIndex is the place of Init in the domination order,
+	// counting from 1
+	OriginalIndex int
+
+	Stats Stats
+
+	AvgClustering float64
+	Density       float64
+	AvgDegree     float64
+
+	EgoTime  time.Duration
+	CoreTime time.Duration
+
+	// Nodes are the node ids of the community
+	Nodes []int
+
+	// the columns derived from the others by Transform
+	Std1  float64
+	Norm  float64
+	W1    float64
+	QNorm float64
+	AD2   float64
+}
+
+// NewRow returns the row of the community c, found
+// for the node at index of the domination order
+func NewRow(c *Community, index int) Row {
+	return Row{
+		Init:          c.Init,
+		OriginalIndex: index,
+		Stats:         c.Stats,
+		AvgClustering: c.Graph.AvgClustering(),
+		Density:       c.Graph.Density(),
+		AvgDegree:     c.Graph.AvgDegree(),
+		EgoTime:       c.EgoTime,
+		CoreTime:      c.CoreTime,
+		Nodes:         c.Graph.IDs(),
+	}
+}
+
+// Results are the communities of a search, one per row
+type Results []Row
+
+// Transform fills in the derived columns and sorts the rows by
+// descending qnorm and ratio_max_k_core, the same as the TRANSFORM
+// step of the scripts:
+//
+//	std1  = 100000 / max_stddev
+//	norm  = std1 / maximum(std1)
+//	w1    = 0.75 norm + 0.25 ratio_max_k_core
+//	qnorm = floor(500 norm)
+//	ad2   = norm avg_degree
+func (res Results) Transform() {
+	maxStd1 := math.Inf(-1)
+	for i := range res {
+		r := &res[i]
+		r.Std1 = 100000 / r.Stats.MaxStddev
+
+		// maximum in Julia propagates NaN
+		if !math.IsNaN(maxStd1) && (math.IsNaN(r.Std1) || r.Std1 > maxStd1) {
+			maxStd1 = r.Std1
+		}
+	}
+
+	for i := range res {
+		r := &res[i]
+		r.Norm = r.Std1 / maxStd1
+		r.W1 = r.Norm*0.75 + r.Stats.RatioMaxKCore*0.25
+		r.QNorm = math.Floor(r.Norm * 500)
+		r.AD2 = r.Norm * r.AvgDegree
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		a, b := res[i], res[j]
+		if isless(b.QNorm, a.QNorm) || isless(a.QNorm, b.QNorm) {
+			return isless(b.QNorm, a.QNorm)
+		}
+		return isless(b.Stats.RatioMaxKCore, a.Stats.RatioMaxKCore)
+	})
+}
+
+// isless orders floats like isless in Julia, with -0.0 before 0.0
+// and NaN after every other value
+func isless(a, b float64) bool {
+	if math.IsNaN(a) {
+		return false
+	}
+	if math.IsNaN(b) {
+		return true
+	}
+	return a < b || (a == b && math.Signbit(a) && !math.Signbit(b))
+}
+
+var resultsHeader = []string{
+	"init", "original_index", "number_of_nodes", "ratio_max_k_core",
+	"max_k_core", "max_stddev", "e2", "e4", "avg_clustering", "density",
+	"avg_degree", "egotime", "kcoretime", "nodes",
+	"std1", "norm", "w1", "qnorm", "ad2",
+}
+
+// Write writes the rows to w as ';' separated values after a header,
+// with the columns and the number format of the results files of the
+// scripts
+func (res Results) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString(strings.Join(resultsHeader, ";"))
+	bw.WriteString("\n")
+
+	for _, r := range res {
+		nodes := strings.Builder{}
+		for _, id := range r.Nodes {
+			nodes.WriteString(strconv.Itoa(id))
+			nodes.WriteString(", ")
+		}
+
+		fields := []string{
+			strconv.Itoa(r.Init),
+			strconv.Itoa(r.OriginalIndex),
+			formatFloat(float64(r.Stats.NumberOfNodes)),
+			formatFloat(r.Stats.RatioMaxKCore),
+			formatFloat(float64(r.Stats.MaxKCore)),
+			formatFloat(r.Stats.MaxStddev),
+			formatFloat(r.Stats.E2),
+			formatFloat(r.Stats.E4),
+			formatFloat(r.AvgClustering),
+			formatFloat(r.Density),
+			formatFloat(r.AvgDegree),
+			formatFloat(r.EgoTime.Seconds()),
+			formatFloat(r.CoreTime.Seconds()),
+			nodes.String(),
+			formatFloat(r.Std1),
+			formatFloat(r.Norm),
+			formatFloat(r.W1),
+			formatFloat(r.QNorm),
+			formatFloat(r.AD2),
+		}
+
+		bw.WriteString(strings.Join(fields, ";"))
+		bw.WriteString("\n")
+	}
+
+	return bw.Flush()
+}
+
+// formatFloat formats v the way Julia prints a Float64, the shortest
+// representation with at least one decimal, switching to an exponent
+// below 1e-4 and from 1e6 on
+func formatFloat(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+
+	abs := math.Abs(v)
+	if abs != 0 && (abs < 1e-4 || abs >= 1e6) {
+		s := strconv.FormatFloat(v, 'e', -1, 64)
+		mantissa, exp, _ := strings.Cut(s, "e")
+		if !strings.Contains(mantissa, ".") {
+			mantissa += ".0"
+		}
+		e, _ := strconv.Atoi(exp)
+		return mantissa + "e" + strconv.Itoa(e)
+	}
+
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s
+}
@@ -0,0 +1,80 @@
+package community
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestFormatFloat(t *testing.T) {
+	for v, want := range map[float64]string{
+		0:           "0.0",
+		3:           "3.0",
+		0.75:        "0.75",
+		-2.5:        "-2.5",
+		0.0001:      "0.0001",
+		0.00001234:  "1.234e-5",
+		123456.5:    "123456.5",
+		1e6:         "1.0e6",
+		1234567:     "1.234567e6",
+		math.Inf(1): "Inf",
+	} {
+		if got := formatFloat(v); got != want {
+			t.Errorf("got %v for %v, want %v", got, v, want)
+		}
+	}
+	if got := formatFloat(math.NaN()); got != "NaN" {
+		t.Errorf("got %v for NaN", got)
+	}
+}
+
+func TestResults(t *testing.T) {
+	s := testSearcher(1)
+	p := s.Partition()
+
+	res := Results{}
+	for idx, id := range Rank(s.Dom) {
+		c, err := p.Community(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c != nil {
+			res = append(res, NewRow(c, idx+1))
+		}
+	}
+
+	res.Transform()
+
+	// the clique of 5 has the lowest max_stddev, then the
+	// triangle of 1 and the tail of 10
+	order := []int{}
+	for _, r := range res {
+		order = append(order, r.Init)
+	}
+	if len(order) != 3 || order[0] != 5 || order[1] != 1 || order[2] != 10 {
+		t.Fatalf("got communities in order %v, want [5 1 10]", order)
+	}
+
+	r := res[0]
+	if r.Norm != 1 || r.QNorm != 500 || r.W1 != 0.75+r.Stats.RatioMaxKCore*0.25 || r.AD2 != r.AvgDegree {
+		t.Errorf("got derived columns %+v", r)
+	}
+	if r.Std1 != 100000/r.Stats.MaxStddev || res[1].QNorm != math.Floor(500*res[1].Std1/r.Std1) {
+		t.Errorf("got std1 %v and qnorm %v", r.Std1, res[1].QNorm)
+	}
+
+	sb := strings.Builder{}
+	if err := res.Write(&sb); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "init;original_index;number_of_nodes;") {
+		t.Fatalf("got %v lines, header %v", len(lines), lines[0])
+	}
+
+	fields := strings.Split(lines[1], ";")
+	if len(fields) != 19 || fields[0] != "5" || fields[1] != "1" || fields[2] != "4.0" || fields[4] != "3.0" || fields[13] != "5, 6, 7, 8, " || fields[17] != "500.0" {
+		t.Errorf("got row %v", lines[1])
+	}
+}
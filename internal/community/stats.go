@@ -0,0 +1,104 @@
+package community
+
+import "math"
+
+// Stats are the measures of a community used to rank the communities,
+// with the names and the formulas of get_graph_stats in common.jl
+type Stats struct {
+	NumberOfNodes int `json:"number_of_nodes"`
+
+	// RatioMaxKCore is MaxKCore over the number of nodes
+	RatioMaxKCore float64 `json:"ratio_max_k_core"`
+	MaxKCore      int     `json:"max_k_core"`
+
+	// MaxStddev is the root mean square distance of the domination
+	// scores of the nodes from the highest score of the dataset
+	MaxStddev float64 `json:"max_stddev"`
+
+	E2 float64 `json:"e2"`
+	E4 float64 `json:"e4"`
+}
+
+// NewStats returns the stats of the community g, whose max k-core has
+// core number k. dom holds the domination score of every node and
+// maxDom the highest one. Nodes without a score count as 0.
+func NewStats(g *Graph, dom map[int]int, maxDom int, k int) Stats {
+	n := g.Order()
+
+	squareSum := 0.0
+	for _, id := range g.IDs() {
+		d := float64(dom[id] - maxDom)
+		squareSum += d * d
+	}
+
+	ratio := float64(k) / float64(n)
+	stddev := math.Sqrt(squareSum / float64(n))
+
+	return Stats{
+		NumberOfNodes: n,
+		RatioMaxKCore: ratio,
+		MaxKCore:      k,
+		MaxStddev:     stddev,
+		E2:            (float64(k) * ratio) / stddev,
+		E4:            (float64(k) + ratio) / stddev,
+	}
+}
+
+// Density returns the ratio of the edges of g to the edges of
+// the complete graph on the same vertices
+func (g *Graph) Density() float64 {
+	n := float64(g.Order())
+	return 2 * float64(g.Size()) / (n * (n - 1))
+}
+
+// AvgDegree returns the mean degree of the vertices of g
+func (g *Graph) AvgDegree() float64 {
+	return 2 * float64(g.Size()) / float64(g.Order())
+}
+
+// LocalClustering returns the local clustering coefficient of every
+// vertex, the ratio of the edges between its neighbours to the pairs
+// of its neighbours. Vertices with fewer than two neighbours get 0.
+func (g *Graph) LocalClustering() []float64 {
+	res := make([]float64, g.Order())
+	mark := make([]bool, g.Order())
+
+	for v := range res {
+		neighbors := g.Neighbors(v)
+		d := len(neighbors)
+		if d < 2 {
+			continue
+		}
+
+		for _, u := range neighbors {
+			mark[u] = true
+		}
+
+		triangles := 0
+		for _, u := range neighbors {
+			for _, w := range g.Neighbors(u) {
+				if mark[w] {
+					triangles++
+				}
+			}
+		}
+
+		for _, u := range neighbors {
+			mark[u] = false
+		}
+
+		// every edge between two neighbours was counted twice
+		res[v] = float64(triangles) / float64(d*(d-1))
+	}
+
+	return res
+}
+
+// AvgClustering returns the mean local clustering coefficient
+func (g *Graph) AvgClustering() float64 {
+	sum := 0.0
+	for _, c := range g.LocalClustering() {
+		sum += c
+	}
+	return sum / float64(g.Order())
+}
@@ -0,0 +1,178 @@
+package domination
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/ngeorgiadis/community-discovery/internal/generator"
+)
+
+// generatedRows returns n rows of d attributes drawn by
+// cmd/datasetGenerator with its default parameters
+func generatedRows(r *rand.Rand, datasetType string, n, d int) []DataRow {
+	g, err := generator.New(r, generator.DefaultParams(datasetType, d))
+	if err != nil {
+		panic(err)
+	}
+
+	rows := make([]DataRow, n)
+	for i := range rows {
+		attrs := make([]float64, d)
+		for j, v := range g.Point() {
+			attrs[j] = float64(v)
+		}
+		rows[i] = DataRow{ID: i, Attrs: attrs}
+	}
+	return rows
+}
+
+var benchmarkDatasets = []struct {
+	datasetType string
+	n, d, grid  int
+}{
+	{"UNIFORM", 20000, 4, 10},
+	{"CORRELATED", 20000, 4, 10},
+	{"ANTI_CORRELATED", 20000, 4, 10},
+	{"CLUSTERED", 20000, 4, 10},
+}
+
+func BenchmarkScore(b *testing.B) {
+	for _, ds := range benchmarkDatasets {
+		rows := generatedRows(rand.New(rand.NewSource(1)), ds.datasetType, ds.n, ds.d)
+
+		for _, approximate := range []bool{false, true} {
+			name := fmt.Sprintf("%v/n=%v/d=%v/approximate=%v", ds.datasetType, ds.n, ds.d, approximate)
+			b.Run(name, func(b *testing.B) {
+				dsc := &DominationScoreCalculator{Workers: 1}
+				for i := 0; i < b.N; i++ {
+					if _, err := dsc.Score(rows, approximate, gridOf(ds.d, ds.grid)); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkExact compares the grid with the divide and conquer
+// counts on the dimensions the latter handles
+func BenchmarkExact(b *testing.B) {
+	for _, datasetType := range []string{"UNIFORM", "CORRELATED", "ANTI_CORRELATED"} {
+		for d := 2; d <= 3; d++ {
+			rows := generatedRows(rand.New(rand.NewSource(1)), datasetType, 20000, d)
+
+			for _, algorithm := range []Algorithm{Grid, DivideAndConquer} {
+				b.Run(fmt.Sprintf("%v/n=%v/d=%v/%v", datasetType, len(rows), d, algorithm), func(b *testing.B) {
+					dsc := &DominationScoreCalculator{Workers: 1, Algorithm: algorithm}
+					for i := 0; i < b.N; i++ {
+						if _, err := dsc.Score(rows, false, gridOf(d, 10)); err != nil {
+							b.Fatal(err)
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+// skewedRows returns n rows of d attributes skewed like the AMiner
+// citation counts, where most values are tiny and a few are huge
+func skewedRows(r *rand.Rand, n, d int) []DataRow {
+	rows := make([]DataRow, n)
+	for i := range rows {
+		attrs := make([]float64, d)
+		for j := range attrs {
+			attrs[j] = float64(int(math.Exp(r.ExpFloat64() * 2)))
+		}
+		rows[i] = DataRow{ID: i, Attrs: attrs}
+	}
+	return rows
+}
+
+// BenchmarkGridMode compares the equal width and the quantile grids
+// on skewed data, where most rows of the equal width grid fall in its
+// first cells
+func BenchmarkGridMode(b *testing.B) {
+	rows := skewedRows(rand.New(rand.NewSource(1)), 20000, 4)
+
+	for _, approximate := range []bool{false, true} {
+		for _, mode := range []GridMode{EqualWidth, Quantile} {
+			b.Run(fmt.Sprintf("SKEWED/n=%v/d=4/approximate=%v/%v", len(rows), approximate, mode), func(b *testing.B) {
+				dsc := &DominationScoreCalculator{Workers: 1, Algorithm: Grid, GridMode: mode}
+				for i := 0; i < b.N; i++ {
+					res, err := dsc.Score(rows, approximate, gridOf(4, 10))
+					if err != nil {
+						b.Fatal(err)
+					}
+					if approximate {
+						b.ReportMetric(NewAccuracy(res.Scores, res.Bounds).MeanWidth, "mean-width")
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkSpatialIndex compares the grid with the k-d tree on
+// generated and skewed data
+func BenchmarkSpatialIndex(b *testing.B) {
+	datasets := map[string][]DataRow{
+		"SKEWED":     skewedRows(rand.New(rand.NewSource(1)), 20000, 4),
+		"UNIFORM":    generatedRows(rand.New(rand.NewSource(1)), "UNIFORM", 20000, 4),
+		"CORRELATED": generatedRows(rand.New(rand.NewSource(1)), "CORRELATED", 20000, 4),
+	}
+
+	for _, name := range []string{"SKEWED", "UNIFORM", "CORRELATED"} {
+		rows := datasets[name]
+		for _, algorithm := range []Algorithm{Grid, KDTree} {
+			b.Run(fmt.Sprintf("%v/n=%v/d=4/%v", name, len(rows), algorithm), func(b *testing.B) {
+				dsc := &DominationScoreCalculator{Workers: 1, Algorithm: algorithm}
+				for i := 0; i < b.N; i++ {
+					if _, err := dsc.Score(rows, false, gridOf(4, 10)); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkTopK(b *testing.B) {
+	for _, ds := range benchmarkDatasets {
+		rows := generatedRows(rand.New(rand.NewSource(1)), ds.datasetType, ds.n, ds.d)
+
+		b.Run(fmt.Sprintf("%v/n=%v/d=%v", ds.datasetType, ds.n, ds.d), func(b *testing.B) {
+			dsc := &DominationScoreCalculator{Workers: 1}
+			for i := 0; i < b.N; i++ {
+				if _, err := dsc.TopK(rows, 10, gridOf(ds.d, ds.grid)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkIndexInsert(b *testing.B) {
+	for _, ds := range benchmarkDatasets {
+		rows := generatedRows(rand.New(rand.NewSource(1)), ds.datasetType, ds.n+1000, ds.d)
+
+		b.Run(fmt.Sprintf("%v/n=%v/d=%v", ds.datasetType, ds.n, ds.d), func(b *testing.B) {
+			dsc := &DominationScoreCalculator{Workers: 1}
+			ix, err := dsc.NewIndex(rows[:ds.n], gridOf(ds.d, ds.grid))
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				row := rows[ds.n+i%1000]
+				row.ID = ds.n + i
+				if err := ix.Insert(row); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package domination
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Bounds holds the lowest and the highest domination score a point can
+// have. In approximate mode the score of a point is exact for the cells
+// that are lower in every coordinate and estimated for the cells that
+// are lower or equal, so the true score lies between the exact part
+// alone and the exact part plus every point of those cells that is not
+// equal to the point itself.
+type Bounds struct {
+	Lower int
+	Upper int
+}
+
+// Width returns the number of scores the bounds allow besides Lower
+func (b Bounds) Width() int {
+	return b.Upper - b.Lower
+}
+
+// Accuracy summarizes how far the approximate scores can be
+// from the true ones, to compare grid sizes with each other
+type Accuracy struct {
+	Rows int
+
+	// Exact is the number of rows whose bounds are equal,
+	// so their approximate score is the true score
+	Exact int
+
+	// Outside is the number of rows whose approximate
+	// score is not between their bounds
+	Outside int
+
+	MeanWidth float64
+	MaxWidth  int
+
+	// MeanError and MaxError are the mean and the largest possible
+	// distance of the approximate score from the true score
+	MeanError float64
+	MaxError  int
+}
+
+// NewAccuracy compares the approximate scores with their bounds.
+// Rows without bounds are left out.
+func NewAccuracy(scores map[int]int, bounds map[int]Bounds) *Accuracy {
+	sums := accuracySums{}
+	for id, b := range bounds {
+		if score, ok := scores[id]; ok {
+			sums.add(score, b)
+		}
+	}
+	return sums.accuracy()
+}
+
+// accuracySums builds an Accuracy one row at a time
+type accuracySums struct {
+	acc      Accuracy
+	widthSum int
+	errorSum int
+}
+
+func (s *accuracySums) add(score int, b Bounds) {
+	acc := &s.acc
+	acc.Rows++
+
+	if b.Lower == b.Upper {
+		acc.Exact++
+	}
+	if score < b.Lower || score > b.Upper {
+		acc.Outside++
+	}
+
+	w := b.Width()
+	s.widthSum += w
+	if w > acc.MaxWidth {
+		acc.MaxWidth = w
+	}
+
+	e := score - b.Lower
+	if b.Upper-score > e {
+		e = b.Upper - score
+	}
+	s.errorSum += e
+	if e > acc.MaxError {
+		acc.MaxError = e
+	}
+}
+
+func (s *accuracySums) accuracy() *Accuracy {
+	acc := s.acc
+	if acc.Rows > 0 {
+		acc.MeanWidth = float64(s.widthSum) / float64(acc.Rows)
+		acc.MeanError = float64(s.errorSum) / float64(acc.Rows)
+	}
+	return &acc
+}
+
+// WriteAccuracy writes the accuracy summary to w, one tab separated
+// name and value per line
+func WriteAccuracy(w io.Writer, acc *Accuracy) error {
+	exact := 0.0
+	if acc.Rows > 0 {
+		exact = 100 * float64(acc.Exact) / float64(acc.Rows)
+	}
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "rows\t%v\n", acc.Rows)
+	fmt.Fprintf(bw, "exact\t%v\t%.2f%%\n", acc.Exact, exact)
+	fmt.Fprintf(bw, "outside bounds\t%v\n", acc.Outside)
+	fmt.Fprintf(bw, "mean width\t%.4f\n", acc.MeanWidth)
+	fmt.Fprintf(bw, "max width\t%v\n", acc.MaxWidth)
+	fmt.Fprintf(bw, "mean error\t%.4f\n", acc.MeanError)
+	fmt.Fprintf(bw, "max error\t%v\n", acc.MaxError)
+
+	return bw.Flush()
+}
+
+// WriteBounds writes the scores and their bounds to w as tab separated
+// id, domination score, lower and upper bound lines, in id order, after
+// an "id\tdom\tlower\tupper" header
+func WriteBounds(w io.Writer, scores map[int]int, bounds map[int]Bounds) error {
+	ids := make([]int, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString("id\tdom\tlower\tupper\n")
+	for _, id := range ids {
+		b := bounds[id]
+		fmt.Fprintf(bw, "%v\t%v\t%v\t%v\n", id, scores[id], b.Lower, b.Upper)
+	}
+
+	return bw.Flush()
+}
+
+// siblingFile returns the name of a file next to filename,
+// with suffix added before the extension
+func siblingFile(filename string, suffix string) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + suffix + ext
+}
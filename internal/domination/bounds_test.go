@@ -0,0 +1,76 @@
+package domination
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExactModeHasNoBounds(t *testing.T) {
+	rows := rowsOf([]float64{1, 1}, []float64{2, 2})
+
+	dsc := &DominationScoreCalculator{}
+	res, err := dsc.Score(rows, false, []int{2, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Bounds != nil {
+		t.Errorf("got bounds %v in exact mode", res.Bounds)
+	}
+}
+
+func TestNewAccuracy(t *testing.T) {
+	scores := map[int]int{1: 5, 2: 3, 3: 9, 4: 1}
+	bounds := map[int]Bounds{
+		1: {Lower: 5, Upper: 5},
+		2: {Lower: 2, Upper: 6},
+		3: {Lower: 4, Upper: 8},
+	}
+
+	acc := NewAccuracy(scores, bounds)
+	want := Accuracy{Rows: 3, Exact: 1, Outside: 1, MeanWidth: 8.0 / 3, MaxWidth: 4, MeanError: 8.0 / 3, MaxError: 5}
+	if *acc != want {
+		t.Errorf("got %+v, want %+v", *acc, want)
+	}
+}
+
+func TestCalcApproximate(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "nodes.tsv")
+	output := filepath.Join(dir, "domination.txt")
+
+	err := os.WriteFile(input, []byte("1\t1\t1\n2\t2\t2\n3\t3\t1\n4\t2\t2\n"), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	columns := ColumnMapping{
+		Delimiter: "\t",
+		ID:        ColumnIndex(0),
+		Attrs:     []Column{ColumnIndex(1), ColumnIndex(2)},
+	}
+
+	dsc := &DominationScoreCalculator{}
+	err = dsc.Calc(&CSVDatasetReader{Columns: columns}, input, output, true, []int{1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "domination_bounds.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 5 || lines[0] != "id\tdom\tlower\tupper" {
+		t.Errorf("got bounds file %q", b)
+	}
+
+	b, err = os.ReadFile(filepath.Join(dir, "domination_accuracy.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), "rows\t4\n") {
+		t.Errorf("got accuracy file %q", b)
+	}
+}
@@ -0,0 +1,294 @@
+package domination
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Column selects a column of a dataset file by its header name or,
+// when Name is empty, by its zero based Index. In the settings.json
+// files a column is given as a string for a name or as a number for
+// an index.
+type Column struct {
+	Name  string
+	Index int
+}
+
+// ColumnIndex returns a Column selecting the column at index i
+func ColumnIndex(i int) Column {
+	return Column{Index: i}
+}
+
+// ColumnName returns a Column selecting the column named name
+func ColumnName(name string) Column {
+	return Column{Name: name}
+}
+
+func (c Column) String() string {
+	if c.Name != "" {
+		return strconv.Quote(c.Name)
+	}
+	return strconv.Itoa(c.Index)
+}
+
+func (c Column) MarshalJSON() ([]byte, error) {
+	if c.Name != "" {
+		return json.Marshal(c.Name)
+	}
+	return json.Marshal(c.Index)
+}
+
+func (c *Column) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		if name == "" {
+			return fmt.Errorf("empty column name")
+		}
+		*c = Column{Name: name}
+		return nil
+	}
+
+	var index int
+	if err := json.Unmarshal(b, &index); err != nil {
+		return fmt.Errorf("column should be a header name or an index, got %s", b)
+	}
+	if index < 0 {
+		return fmt.Errorf("negative column index %v", index)
+	}
+	*c = Column{Index: index}
+	return nil
+}
+
+// ColumnMapping describes the layout of a delimited dataset file
+type ColumnMapping struct {
+	// Delimiter separates the fields, "," when empty
+	Delimiter string `json:"delimiter"`
+
+	// Header is true when the first row holds the column names
+	Header bool `json:"header"`
+
+	ID Column `json:"id"`
+
+	// Name is optional, rows are left without a name when nil
+	Name *Column `json:"name"`
+
+	// Attrs are the attribute columns, in dimension order
+	Attrs []Column `json:"attrs"`
+}
+
+// AminerColumns returns the mapping of the AMiner author files, with the
+// id and name columns followed by the first dimensions of the pc, cn, hi
+// and pi columns
+func AminerColumns(dimensions int) (ColumnMapping, error) {
+	if dimensions < 1 || dimensions > 4 {
+		return ColumnMapping{}, fmt.Errorf("dataset dimensions should be 1 to 4, got %v", dimensions)
+	}
+
+	name := ColumnIndex(1)
+	m := ColumnMapping{
+		Delimiter: ",",
+		Header:    true,
+		ID:        ColumnIndex(0),
+		Name:      &name,
+	}
+
+	for i := 0; i < dimensions; i++ {
+		m.Attrs = append(m.Attrs, ColumnIndex(2+i))
+	}
+
+	return m, nil
+}
+
+// comma returns the delimiter as a rune
+func (m *ColumnMapping) comma() (rune, error) {
+	if m.Delimiter == "" {
+		return ',', nil
+	}
+
+	r, size := utf8.DecodeRuneInString(m.Delimiter)
+	if r == utf8.RuneError || size != len(m.Delimiter) {
+		return 0, fmt.Errorf("delimiter should be a single character, got %q", m.Delimiter)
+	}
+	return r, nil
+}
+
+// resolve returns the indices of the id, name and attribute columns,
+// reading the header row of rr to find the columns given by name.
+// The name index is -1 when there is no name column.
+func (m *ColumnMapping) resolve(rr *RecordReader) (int, int, []int, error) {
+	if len(m.Attrs) == 0 {
+		return 0, 0, nil, fmt.Errorf("no attribute columns given")
+	}
+
+	header := map[string]int{}
+	if m.Header {
+		if !rr.Next() {
+			if err := rr.Err(); err != nil {
+				return 0, 0, nil, err
+			}
+			return 0, 0, nil, fmt.Errorf("%v: missing header row", rr.File)
+		}
+		if len(rr.errs) > 0 {
+			return 0, 0, nil, rr.errs[0]
+		}
+
+		for i, name := range rr.record {
+			if _, ok := header[name]; !ok {
+				header[name] = i
+			}
+		}
+	}
+
+	index := func(c Column) (int, error) {
+		if c.Name == "" {
+			return c.Index, nil
+		}
+		if !m.Header {
+			return 0, fmt.Errorf("column %v is given by name but the file has no header row", c)
+		}
+		i, ok := header[c.Name]
+		if !ok {
+			return 0, fmt.Errorf("%v: column %v not found in the header row", rr.File, c)
+		}
+		return i, nil
+	}
+
+	id, err := index(m.ID)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	name := -1
+	if m.Name != nil {
+		name, err = index(*m.Name)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+	}
+
+	attrs := make([]int, len(m.Attrs))
+	for i, c := range m.Attrs {
+		attrs[i], err = index(c)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+	}
+
+	return id, name, attrs, nil
+}
+
+// CSVDatasetReader reads delimited dataset files
+// with the layout given by Columns
+type CSVDatasetReader struct {
+	Columns    ColumnMapping
+	Strictness Strictness
+
+	// Directions holds the preference direction of every attribute,
+	// so that Impute fills in the worst value of each, see
+	// DatasetBuilder.Directions
+	Directions []Direction
+}
+
+// ReadDataset reads the csv file and returns
+// a. the data in a map[int]DataRow structure
+// b. a DataStats structure
+// c. a slice with all unique data points
+func (cr *CSVDatasetReader) ReadDataset(filename string) (map[int]DataRow, *DataStats, []DataPoint, error) {
+	b := NewDatasetBuilder(len(cr.Columns.Attrs))
+	b.Directions = cr.Directions
+
+	err := cr.read(filename, b.AddRecord)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	res, stats, dataPoints := b.Build()
+	return res, stats, dataPoints, nil
+}
+
+// ReadPoints reads the csv file and returns its stats and unique data
+// points, without keeping the rows
+func (cr *CSVDatasetReader) ReadPoints(filename string) (*DataStats, []DataPoint, error) {
+	b := newPointsBuilder(len(cr.Columns.Attrs))
+	b.Directions = cr.Directions
+
+	err := cr.read(filename, b.AddRecord)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, stats, dataPoints := b.Build()
+	return stats, dataPoints, nil
+}
+
+// StreamRows reads the csv file again after ReadPoints and calls fn with
+// every row the points were made of, in file order. Invalid attributes
+// are imputed with the worst values of stats, the same values ReadPoints
+// gave them.
+func (cr *CSVDatasetReader) StreamRows(filename string, stats *DataStats, fn func(row DataRow) error) error {
+	worst := worstValues(stats, cr.Directions)
+
+	return cr.read(filename, func(rr *RecordReader, row DataRow) error {
+		if len(rr.errs) == 0 {
+			return fn(row)
+		}
+
+		switch rr.Strictness {
+		case SkipRow:
+			return nil
+
+		case Impute:
+			if rr.otherErrs > 0 {
+				return nil
+			}
+
+			for _, j := range rr.invalidAttrs {
+				row.Attrs[j] = worst[j]
+			}
+			return fn(row)
+		}
+
+		return rr.errs[0]
+	})
+}
+
+// read reads the csv file record by record and calls fn with the
+// reader and the row parsed from each record
+func (cr *CSVDatasetReader) read(filename string, fn func(rr *RecordReader, row DataRow) error) error {
+	comma, err := cr.Columns.comma()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := NewRecordReader(f, filename, comma, cr.Strictness)
+
+	id, name, attrs, err := cr.Columns.resolve(r)
+	if err != nil {
+		return err
+	}
+
+	for r.Next() {
+		row := DataRow{
+			ID:    r.Int(id),
+			Attrs: r.Attrs(attrs...),
+		}
+		if name >= 0 {
+			row.Name = r.Field(name)
+		}
+
+		if err := fn(r, row); err != nil {
+			return err
+		}
+	}
+
+	return r.Err()
+}
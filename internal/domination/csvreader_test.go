@@ -0,0 +1,220 @@
+package domination
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "nodes.csv")
+	if err := os.WriteFile(filename, []byte(content), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestColumnMappingJSON(t *testing.T) {
+	m := ColumnMapping{}
+	err := json.Unmarshal([]byte(`{"delimiter": "\t", "header": true, "id": "Id", "name": 1, "attrs": ["pc", 3, "pi"]}`), &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := ColumnIndex(1)
+	want := ColumnMapping{
+		Delimiter: "\t",
+		Header:    true,
+		ID:        ColumnName("Id"),
+		Name:      &name,
+		Attrs:     []Column{ColumnName("pc"), ColumnIndex(3), ColumnName("pi")},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("got %+v, want %+v", m, want)
+	}
+
+	for _, bad := range []string{`{"id": -1}`, `{"id": ""}`, `{"id": true}`} {
+		if err := json.Unmarshal([]byte(bad), &m); err == nil {
+			t.Errorf("expected an error for %v", bad)
+		}
+	}
+}
+
+func TestCSVDatasetReader(t *testing.T) {
+	filename := writeFile(t, "Id\tLabel\tpc\tcn\thi\tpi\textra\n"+
+		"1\talice\t10\t20\t3\t1.25\tx\n"+
+		"2\tbob\t10\t20\t3\t1.5\ty\n"+
+		"3\tcarol\t1\t2\t3\t0.5\tz\n")
+
+	name := ColumnName("Label")
+	cr := &CSVDatasetReader{
+		Columns: ColumnMapping{
+			Delimiter: "\t",
+			Header:    true,
+			ID:        ColumnName("Id"),
+			Name:      &name,
+			Attrs:     []Column{ColumnName("pi"), ColumnIndex(2), ColumnName("cn"), ColumnName("hi"), ColumnIndex(3)},
+		},
+	}
+
+	rows, stats, unique, err := cr.ReadDataset(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int]DataRow{
+		1: {ID: 1, Name: "alice", Attrs: []float64{1.25, 10, 20, 3, 20}},
+		2: {ID: 2, Name: "bob", Attrs: []float64{1.5, 10, 20, 3, 20}},
+		3: {ID: 3, Name: "carol", Attrs: []float64{0.5, 1, 2, 3, 2}},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("got rows %v, want %v", rows, want)
+	}
+	if len(unique) != 3 || stats.Count != 3 {
+		t.Errorf("got %v unique points and count %v, want 3 and 3", len(unique), stats.Count)
+	}
+	if !reflect.DeepEqual(stats.Max, []float64{1.5, 10, 20, 3, 20}) || !reflect.DeepEqual(stats.Min, []float64{0.5, 1, 2, 3, 2}) {
+		t.Errorf("got max %v and min %v", stats.Max, stats.Min)
+	}
+
+	cr.Columns.Attrs = append(cr.Columns.Attrs, ColumnName("missing"))
+	if _, _, _, err := cr.ReadDataset(filename); err == nil {
+		t.Error("expected an error for a column missing from the header")
+	}
+}
+
+func TestCSVDatasetReaderStrictness(t *testing.T) {
+	filename := writeFile(t, "1,1,2\n"+
+		"2,x,3\n"+
+		"3,4\n"+
+		"y,5,5\n"+
+		"5,\"7,8\n")
+
+	columns := ColumnMapping{
+		ID:    ColumnIndex(0),
+		Attrs: []Column{ColumnIndex(1), ColumnIndex(2)},
+	}
+
+	_, _, _, err := (&CSVDatasetReader{Columns: columns}).ReadDataset(filename)
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("got error %v, want a ParseError", err)
+	}
+	if pe.File != filename || pe.Line != 2 || pe.Column != 3 || pe.Field != 1 {
+		t.Errorf("got error at %v:%v:%v field %v", pe.File, pe.Line, pe.Column, pe.Field)
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("got error %v, want a syntax error", err)
+	}
+
+	rows, stats, _, err := (&CSVDatasetReader{Columns: columns, Strictness: SkipRow}).ReadDataset(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || stats.Skipped != 4 || stats.Imputed != 0 {
+		t.Errorf("skip: got %v rows, %v skipped and %v imputed", len(rows), stats.Skipped, stats.Imputed)
+	}
+
+	rows, stats, _, err = (&CSVDatasetReader{Columns: columns, Strictness: Impute}).ReadDataset(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || stats.Skipped != 2 || stats.Imputed != 2 {
+		t.Errorf("impute: got %v rows, %v skipped and %v imputed", len(rows), stats.Skipped, stats.Imputed)
+	}
+
+	// imputed values are the minimums of the valid values,
+	// see TestImputeMinimize for minimized attributes
+	if !reflect.DeepEqual(rows[2].Attrs, []float64{1, 3}) || !reflect.DeepEqual(rows[3].Attrs, []float64{4, 2}) {
+		t.Errorf("impute: got rows %v", rows)
+	}
+
+	_, _, _, err = (&CSVDatasetReader{Columns: columns}).ReadDataset(filepath.Join(t.TempDir(), "missing.csv"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got error %v, want a missing file error", err)
+	}
+}
+
+// TestCSVDatasetReaderQuiet checks that reading leaves stdout alone,
+// progress goes to DominationScoreCalculator.Log
+func TestCSVDatasetReaderQuiet(t *testing.T) {
+	input := writeFile(t, "1,1,2\n2,3,1\n")
+	cr := &CSVDatasetReader{
+		Columns: ColumnMapping{Attrs: []Column{ColumnIndex(1), ColumnIndex(2)}},
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	_, _, _, err = cr.ReadDataset(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = cr.ReadPoints(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = (&DominationScoreCalculator{}).Calc(cr, input, filepath.Join(t.TempDir(), "domination.txt"), false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Stdout = stdout
+	w.Close()
+	b, _ := io.ReadAll(r)
+	if len(b) > 0 {
+		t.Errorf("got %q on stdout", b)
+	}
+}
+
+// TestImputeMinimize checks that a malformed cost does not make a row
+// look like the cheapest one, on both the in memory and streaming paths
+func TestImputeMinimize(t *testing.T) {
+	input := writeFile(t, "1,10,1\n2,20,2\n3,bad,5\n")
+	cr := &CSVDatasetReader{
+		Columns:    ColumnMapping{ID: ColumnIndex(0), Attrs: []Column{ColumnIndex(1), ColumnIndex(2)}},
+		Strictness: Impute,
+		Directions: []Direction{Minimize, Maximize},
+	}
+
+	rows, _, _, err := cr.ReadDataset(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rows[3].Attrs, []float64{20, 5}) {
+		t.Errorf("got row 3 %v, want the largest cost", rows[3].Attrs)
+	}
+
+	stats, _, err := cr.ReadPoints(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cr.StreamRows(input, stats, func(row DataRow) error {
+		if row.ID == 3 && !reflect.DeepEqual(row.Attrs, []float64{20, 5}) {
+			t.Errorf("streamed row 3 %v, want the largest cost", row.Attrs)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// row 3 only dominates row 2, which costs as much
+	res, err := (&DominationScoreCalculator{Directions: cr.Directions}).ScoreFile(cr, input, false, []int{2, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertScores(t, res.Scores, map[int]int{1: 0, 2: 0, 3: 1})
+}
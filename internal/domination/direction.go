@@ -0,0 +1,167 @@
+package domination
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Direction defines whether larger or smaller
+// values of an attribute are better
+type Direction int
+
+const (
+	// Maximize prefers larger values, like citation counts
+	Maximize Direction = iota
+
+	// Minimize prefers smaller values, like a rank position
+	Minimize
+)
+
+// ParseDirections converts the direction names used in the
+// settings.json files ("max" and "min") to Directions
+func ParseDirections(names []string) ([]Direction, error) {
+	res := make([]Direction, len(names))
+	for i, name := range names {
+		switch strings.ToLower(name) {
+		case "max", "maximize":
+			res[i] = Maximize
+		case "min", "minimize":
+			res[i] = Minimize
+		default:
+			return nil, fmt.Errorf("unknown direction %q for attribute %v, should be max or min", name, i)
+		}
+	}
+	return res, nil
+}
+
+// String returns the name of d used in the settings.json files
+func (d Direction) String() string {
+	if d == Minimize {
+		return "min"
+	}
+	return "max"
+}
+
+// dominates reports whether a dominates b
+// when compared in the given directions
+func dominates(a, b []float64, directions []Direction) bool {
+	better := false
+	for i := range a {
+		x, y := a[i], b[i]
+		if i < len(directions) && directions[i] == Minimize {
+			x, y = y, x
+		}
+
+		if x < y {
+			return false
+		}
+		if x > y {
+			better = true
+		}
+	}
+	return better
+}
+
+// orientation negates the minimized attributes of a dataset, so that
+// larger is better on every attribute and the grid, the sort order and
+// the dominance checks can stay unaware of the directions. Negation is
+// exact for floating point values and negating twice gives back the
+// original values.
+type orientation struct {
+	directions []Direction
+	stats      *DataStats
+}
+
+// orient returns the orientation of a dataset for the directions of dsc
+func (dsc *DominationScoreCalculator) orient(stats *DataStats) (*orientation, error) {
+	if len(dsc.Directions) > 0 && len(stats.Max) > 0 && len(dsc.Directions) != len(stats.Max) {
+		return nil, fmt.Errorf("%v directions given for %v attributes", len(dsc.Directions), len(stats.Max))
+	}
+
+	o := &orientation{stats: stats}
+	if len(stats.Max) == 0 {
+		return o, nil
+	}
+
+	for _, d := range dsc.Directions {
+		if d == Minimize {
+			o.directions = dsc.Directions
+			break
+		}
+	}
+
+	return o, nil
+}
+
+// identity reports whether every attribute is maximized
+func (o *orientation) identity() bool {
+	return o.directions == nil
+}
+
+// attrs returns a with the minimized attributes negated
+func (o *orientation) attrs(a []float64) []float64 {
+	if o.identity() {
+		return a
+	}
+
+	res := make([]float64, len(a))
+	for i := range a {
+		res[i] = a[i]
+		if o.directions[i] == Minimize {
+			res[i] = -a[i]
+		}
+	}
+	return res
+}
+
+// points returns the points with the minimized attributes negated
+func (o *orientation) points(points []DataPoint) []DataPoint {
+	if o.identity() {
+		return points
+	}
+
+	res := make([]DataPoint, len(points))
+	for i, p := range points {
+		res[i] = DataPoint{
+			Attrs: o.attrs(p.Attrs),
+			Count: p.Count,
+		}
+	}
+	return res
+}
+
+// dataStats returns the stats of the oriented dataset, where the
+// minimum of a minimized attribute is its negated maximum and
+// the other way around
+func (o *orientation) dataStats() *DataStats {
+	if o.identity() {
+		return o.stats
+	}
+
+	res := *o.stats
+	res.Max = make([]float64, len(o.stats.Max))
+	res.Min = make([]float64, len(o.stats.Min))
+	res.Histogram = make([]map[float64]int, len(o.stats.Histogram))
+
+	for i := range o.stats.Max {
+		res.Max[i] = o.stats.Max[i]
+		res.Min[i] = o.stats.Min[i]
+		if o.directions[i] == Minimize {
+			res.Max[i] = -o.stats.Min[i]
+			res.Min[i] = -o.stats.Max[i]
+		}
+	}
+
+	for i, h := range o.stats.Histogram {
+		if o.directions[i] != Minimize || h == nil {
+			res.Histogram[i] = h
+			continue
+		}
+
+		res.Histogram[i] = make(map[float64]int, len(h))
+		for v, c := range h {
+			res.Histogram[i][-v] = c
+		}
+	}
+	return &res
+}
@@ -0,0 +1,76 @@
+package domination
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestParseDirections(t *testing.T) {
+	got, err := ParseDirections([]string{"max", "MIN", "maximize"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []Direction{Maximize, Minimize, Maximize}) {
+		t.Errorf("got %v", got)
+	}
+
+	if _, err := ParseDirections([]string{"up"}); err == nil {
+		t.Error("expected an error for an unknown direction")
+	}
+}
+
+func TestDirections(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+
+	for d := 2; d <= 4; d++ {
+		for _, max := range []int{4, 1000} {
+			rows := randomRows(r, 1+r.Intn(300), d, max, false)
+
+			directions := make([]Direction, d)
+			for i := range directions {
+				directions[i] = Direction(r.Intn(2))
+			}
+			directions[0] = Minimize
+
+			name := fmt.Sprintf("%vD max %v %v", d, max, directions)
+			t.Run(name, func(t *testing.T) {
+				dsc := &DominationScoreCalculator{Directions: directions}
+
+				for _, size := range []int{1, 3, 10} {
+					res, err := dsc.Score(rows, false, gridOf(d, size))
+					if err != nil {
+						t.Fatal(err)
+					}
+					assertScores(t, res.Scores, ReferenceScores(rows, directions...))
+
+					sky, err := dsc.SkylineRows(rows, gridOf(d, size))
+					if err != nil {
+						t.Fatal(err)
+					}
+					ids := []int{}
+					for _, row := range sky.Rows {
+						ids = append(ids, row.ID)
+					}
+					if got, want := fmt.Sprint(ids), fmt.Sprint(referenceSkyline(rows, directions...)); got != want {
+						t.Errorf("grid %v: got skyline %v, want %v", size, got, want)
+					}
+
+					top, err := dsc.TopK(rows, 10, gridOf(d, size))
+					if err != nil {
+						t.Fatal(err)
+					}
+					if want := referenceTopK(rows, 10, directions...); !reflect.DeepEqual(top, want) {
+						t.Errorf("grid %v: got top-10 %v, want %v", size, top, want)
+					}
+				}
+			})
+		}
+	}
+
+	dsc := &DominationScoreCalculator{Directions: []Direction{Minimize}}
+	if _, err := dsc.Score(rowsOf([]float64{1, 2}), false, []int{2, 2}); err == nil {
+		t.Error("expected an error for fewer directions than attributes")
+	}
+}
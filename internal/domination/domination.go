@@ -1,14 +1,13 @@
 package domination
 
 import (
-	"encoding/csv"
 	"fmt"
-	"log"
+	"io"
 	"math"
 	"os"
+	"runtime"
 	"sort"
-	"strconv"
-	"strings"
+	"sync"
 	"time"
 )
 
@@ -16,31 +15,72 @@ import (
 type DataRow struct {
 	ID    int
 	Name  string
-	Attrs []int
+	Attrs []float64
 }
 
 type DataStats struct {
+	// Count is the number of rows read, repeated ids included,
+	// since every row counts in the unique points
 	Count int
-	Max   []int
-	Min   []int
+	Max   []float64
+	Min   []float64
 
-	Histogram []map[int]int
+	// Skipped is the number of malformed rows left out and Imputed
+	// the number of malformed values replaced, see Strictness
+	Skipped int
+	Imputed int
+
+	Histogram []map[float64]int
 }
 
 type DataPoint struct {
-	Attrs []int
+	Attrs []float64
 	Count int
 }
 
 // DominationScoreCalculator ...
 type DominationScoreCalculator struct {
+	// Workers is the number of goroutines the grid cells of the main
+	// loop are partitioned across. Values lower than 1 fall back to
+	// runtime.GOMAXPROCS; 1 runs the main loop single-threaded.
+	Workers int
+
+	// Log receives the progress and timing messages,
+	// nothing is printed when it is nil
+	Log io.Writer
+
+	// Directions holds the preference direction of every attribute.
+	// When empty, larger values are better on every attribute.
+	Directions []Direction
+
+	// Algorithm selects how exact scores are counted, by the
+	// number of attributes when left to Auto
+	Algorithm Algorithm
+
+	// GridMode selects where the boundaries of the grid cells
+	// lie, for the grid based scores, top-k and skyline
+	GridMode GridMode
+
+	// GridSample is the number of unique points the candidates for an
+	// automatic grid size are timed on. When 0, the grid size is picked
+	// from the number of unique points and the histogram alone.
+	GridSample int
 }
 
 func New() *DominationScoreCalculator {
-	return &DominationScoreCalculator{}
+	return &DominationScoreCalculator{
+		Workers: runtime.GOMAXPROCS(0),
+		Log:     os.Stdout,
+	}
+}
+
+func (dsc *DominationScoreCalculator) logf(format string, a ...interface{}) {
+	if dsc.Log != nil {
+		fmt.Fprintf(dsc.Log, format, a...)
+	}
 }
 
-func a_equals_b(a, b []int) bool {
+func a_equals_b(a, b []float64) bool {
 	for i := range a {
 		if a[i] != b[i] {
 			return false
@@ -49,7 +89,7 @@ func a_equals_b(a, b []int) bool {
 	return true
 }
 
-func a_greater_or_equal_to_b(a, b []int) bool {
+func a_greater_or_equal_to_b(a, b []float64) bool {
 	for i := range a {
 		if a[i] < b[i] {
 			return false
@@ -60,7 +100,7 @@ func a_greater_or_equal_to_b(a, b []int) bool {
 
 // a_dominates_b function need to be optimized
 // to perform best
-func a_dominates_b(a, b []int) bool {
+func a_dominates_b(a, b []float64) bool {
 
 	// if (a[0] == b[0]) &&
 	// 	(a[1] == b[1]) &&
@@ -81,7 +121,7 @@ func a_dominates_b(a, b []int) bool {
 	return a_greater_or_equal_to_b(a, b)
 }
 
-func a_less_b(a, b []int) bool {
+func a_less_b(a, b []float64) bool {
 	// return ((a[0] < b[0]) &&
 	// 	(a[1] < b[1]) &&
 	// 	(a[2] < b[2]) &&
@@ -95,7 +135,7 @@ func a_less_b(a, b []int) bool {
 	return true
 }
 
-func a_less_or_equal_b(a, b []int) bool {
+func a_less_or_equal_b(a, b []float64) bool {
 	// return ((a[0] <= b[0]) &&
 	// 	(a[1] <= b[1]) &&
 	// 	(a[2] <= b[2]) &&
@@ -108,29 +148,29 @@ func a_less_or_equal_b(a, b []int) bool {
 	return true
 }
 
-func getKey(a []int) string {
-	res := ""
-	for i := range a {
-		res += fmt.Sprintf("%v|", a[i])
-	}
-	return res
-}
+// translate returns the coordinates of the grid cell p falls in. The
+// coordinates are whole numbers; floor keeps the cells of negative
+// values, which minimized attributes are turned into, as wide as the
+// rest.
+func translate(p []float64, stats *DataStats, gridSize ...int) []float64 {
 
-func translate(p []int, stats *DataStats, gridSize ...int) []int {
-
-	res := make([]int, len(p))
+	res := make([]float64, len(p))
 
 	for i := range p {
-		steps := float64(stats.Max[i]-stats.Min[i]) / float64(gridSize[i])
-		n, _ := math.Modf(float64(p[i]) / steps)
-		res[i] = int(1 + n)
-		// res[i] = int(float64(p[i]) / steps)
+		steps := (stats.Max[i] - stats.Min[i]) / float64(gridSize[i])
+		if steps == 0 {
+			// every value of the attribute is the same
+			res[i] = 1
+			continue
+		}
+
+		res[i] = 1 + math.Floor(p[i]/steps)
 	}
 
 	return res
 }
 
-func translateApprx(p []int, stats *DataStats, gridSize ...int) float64 {
+func translateApprx(p []float64, stats *DataStats, gridSize ...int) float64 {
 
 	if a_equals_b(stats.Min, p) {
 		return 0
@@ -139,16 +179,20 @@ func translateApprx(p []int, stats *DataStats, gridSize ...int) float64 {
 	res := 1.0
 
 	for i := range p {
-		steps := float64(stats.Max[i]-stats.Min[i]) / float64(gridSize[i])
-		_, f := math.Modf(float64(1+p[i]) / steps)
-		res = res * f
+		steps := (stats.Max[i] - stats.Min[i]) / float64(gridSize[i])
+		if steps == 0 {
+			continue
+		}
+
+		x := (1 + p[i]) / steps
+		res = res * (x - math.Floor(x))
 	}
 
 	return res
 }
 
-func sumSlice(n []int) int {
-	s := 0
+func sumSlice(n []float64) float64 {
+	s := 0.0
 	for i := range n {
 		s += n[i]
 	}
@@ -156,11 +200,9 @@ func sumSlice(n []int) int {
 }
 
 type DatasetReader interface {
-	ReadDataset(filename string) (map[int]DataRow, *DataStats, []DataPoint)
+	ReadDataset(filename string) (map[int]DataRow, *DataStats, []DataPoint, error)
 }
 
-type DefaultDatasetReader struct{}
-
 // type DominationChecker interface {
 // 	Dominates(a, b []int) bool
 // }
@@ -181,314 +223,506 @@ type DefaultDatasetReader struct{}
 // 		(a[3] >= b[3])
 // }
 
-// ReadDatasetRows reads the csv file and returns
-// a. the data in a map[int]DataRow structure
-// b. a DataStats structure
-// c. a slice with all unique data points
-func (ddr *DefaultDatasetReader) ReadDataset(filename string) (map[int]DataRow, *DataStats, []DataPoint) {
-	f, _ := os.Open(filename)
-	r := csv.NewReader(f)
+func datapointSortFn(data []DataPoint) func(i, j int) bool {
+	return func(i, j int) bool {
+		return sortsBefore(data[i].Attrs, data[j].Attrs)
+	}
+}
 
-	fmt.Println("start")
-	fmt.Println("reading...")
+// sortsBefore orders points by descending sum of their
+// values and then by ascending spread of the values
+func sortsBefore(a1, a2 []float64) bool {
+	s1 := 0.0
+	for i1 := range a1 {
+		s1 += a1[i1]
+	}
 
-	recs, err := r.ReadAll()
-	if err != nil {
-		log.Fatal(err)
+	s2 := 0.0
+	for i2 := range a2 {
+		s2 += a2[i2]
 	}
 
-	res := map[int]DataRow{}
-	stats := &DataStats{
-		Max:       make([]int, 4),
-		Min:       make([]int, 4),
-		Histogram: make([]map[int]int, 4),
-		Count:     0,
+	avg1 := s1 / float64(len(a1))
+	sd1 := 0.0
+	for i1 := range a1 {
+		sd1 += math.Pow(avg1-a1[i1], 2.0)
 	}
 
-	stats.Max = []int{math.MinInt64, math.MinInt64, math.MinInt64, math.MinInt64}
-	stats.Min = []int{math.MaxInt64, math.MaxInt64, math.MaxInt64, math.MaxInt64}
+	avg2 := s2 / float64(len(a2))
+	sd2 := 0.0
+	for i2 := range a2 {
+		sd2 += math.Pow(avg2-a2[i2], 2.0)
+	}
+
+	if s1 > s2 {
+		return true
+	} else if s1 == s2 {
+		return sd1 < sd2
+	} else {
+		return false
+	}
+}
 
-	unique := map[string]int{}
+// Calc reads the dataset in inputFile with dataReader and writes the
+// domination score of every row to outputFile
+func (dsc *DominationScoreCalculator) Calc(dataReader DatasetReader, inputFile string, outputFile string, approximate bool, gridSize []int) error {
+	total := time.Now()
 
-	for i, row := range recs {
-		if i == 0 {
-			continue
+	res, err := dsc.ScoreFile(dataReader, inputFile, approximate, gridSize)
+	if err != nil {
+		return err
+	}
+
+	t1 := time.Now()
+
+	// write outfile
+	fd, err := os.Create(outputFile)
+	if err != nil {
+		fd, err = os.Create("dom_out_new.txt")
+		if err != nil {
+			return err
 		}
+	}
 
-		id, _ := strconv.Atoi(row[0])
-		pc, _ := strconv.Atoi(row[2])
-		cn, _ := strconv.Atoi(row[3])
-		hi, _ := strconv.Atoi(row[4])
+	err = WriteScores(fd, res.Scores)
+	if err != nil {
+		fd.Close()
+		return err
+	}
 
-		pi, _ := strconv.ParseFloat(row[5], 64)
-		pi = math.Trunc(pi)
+	err = fd.Close()
+	if err != nil {
+		return err
+	}
 
-		attrs := []int{
-			pc, cn, hi, int(pi),
+	if approximate {
+		err = dsc.writeBounds(fd.Name(), res)
+		if err != nil {
+			return err
 		}
+	}
 
-		// stats max
-		{
-			if pc > stats.Max[0] {
-				stats.Max[0] = pc
-			}
+	res.Timings.Write = time.Since(t1)
+	res.Timings.Total = time.Since(total)
+	dsc.logf("write results to file done in: %v\n", res.Timings.Write)
+	dsc.logf("%v\n", res.Timings.Total)
 
-			if cn > stats.Max[1] {
-				stats.Max[1] = cn
-			}
+	m, err := dsc.newManifest(total, inputFile, fd.Name(), approximate, res.GridSize, res.Stats, res.Points, res.Timings)
+	if err != nil {
+		return err
+	}
+	m.AutoGridSize = len(gridSize) == 0
+	return dsc.writeManifest(fd.Name(), m)
+}
 
-			if hi > stats.Max[2] {
-				stats.Max[2] = hi
-			}
+// writeBounds writes the bounds of the approximate scores next to
+// outputFile, along with the accuracy summary, which is also logged
+func (dsc *DominationScoreCalculator) writeBounds(outputFile string, res *Result) error {
+	acc := NewAccuracy(res.Scores, res.Bounds)
 
-			if int(pi) > stats.Max[3] {
-				stats.Max[3] = int(pi)
-			}
+	err := createFile(siblingFile(outputFile, "_bounds"), func(w io.Writer) error {
+		return WriteBounds(w, res.Scores, res.Bounds)
+	})
+	if err != nil {
+		return err
+	}
 
-			// stats min
-			if pc < stats.Min[0] {
-				stats.Min[0] = pc
-			}
+	return dsc.writeAccuracy(outputFile, acc)
+}
 
-			if cn < stats.Min[1] {
-				stats.Min[1] = cn
-			}
+// writeAccuracy writes the accuracy summary next to
+// outputFile and logs it
+func (dsc *DominationScoreCalculator) writeAccuracy(outputFile string, acc *Accuracy) error {
+	err := createFile(siblingFile(outputFile, "_accuracy"), func(w io.Writer) error {
+		return WriteAccuracy(w, acc)
+	})
+	if err != nil {
+		return err
+	}
 
-			if hi < stats.Min[2] {
-				stats.Min[2] = hi
-			}
+	if dsc.Log != nil {
+		return WriteAccuracy(dsc.Log, acc)
+	}
+	return nil
+}
 
-			if int(pi) < stats.Min[3] {
-				stats.Min[3] = int(pi)
-			}
-		}
+// createFile creates filename and writes to it with write
+func createFile(filename string, write func(w io.Writer) error) error {
+	fd, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
 
-		// histogram
-		{
-			for i, a := range attrs {
-				if stats.Histogram[i] == nil {
-					stats.Histogram[i] = map[int]int{}
-				}
-				stats.Histogram[i][a]++
-			}
-		}
+	err = write(fd)
+	if err != nil {
+		fd.Close()
+		return err
+	}
 
-		// unique
-		{
-			attrKey := ""
-			for i := range attrs {
-				attrKey += fmt.Sprintf("%v|", attrs[i])
-			}
+	return fd.Close()
+}
 
-			unique[attrKey]++
-		}
+// ScoreFile reads the dataset in inputFile with dataReader and
+// returns the domination score of every row
+func (dsc *DominationScoreCalculator) ScoreFile(dataReader DatasetReader, inputFile string, approximate bool, gridSize []int) (*Result, error) {
+	total := time.Now()
 
-		res[id] = DataRow{
-			ID:    id,
-			Name:  row[1],
-			Attrs: attrs,
-		}
+	dsc.logf("reading %v...\n", inputFile)
 
+	t1 := time.Now()
+	rows, stats, unique, err := dataReader.ReadDataset(inputFile)
+	if err != nil {
+		return nil, err
 	}
+	read := time.Since(t1)
 
-	dataPoints := []DataPoint{}
+	dsc.logf("reading done in: %v\n", read)
+	if stats.Skipped > 0 || stats.Imputed > 0 {
+		dsc.logf("skipped rows: %v, imputed values: %v\n", stats.Skipped, stats.Imputed)
+	}
 
-	for k, v := range unique {
+	res, err := dsc.score(rows, stats, unique, approximate, gridSize)
+	if err != nil {
+		return nil, err
+	}
+	res.Timings.Read = read
+	res.Timings.Total = time.Since(total)
 
-		attrs := strings.Split(strings.TrimRight(k, "|"), "|")
+	return res, nil
+}
 
-		a := []int{}
-		for i := range attrs {
-			iv, _ := strconv.Atoi(attrs[i])
-			a = append(a, iv)
-		}
+// gridCell is a non empty cell of the grid
+type gridCell struct {
+	coords []float64
 
-		dataPoints = append(dataPoints, DataPoint{
-			Count: v,
-			Attrs: a,
-		})
-	}
+	// sum is the sum of coords
+	sum float64
 
-	stats.Count = len(res)
-	return res, stats, dataPoints
+	// points are the unique points in the cell and count
+	// the number of rows they stand for
+	points []DataPoint
+	count  int
 }
 
-func datapointSortFn(data []DataPoint) func(i, j int) bool {
-	return func(i, j int) bool {
-		a1 := data[i]
-		a2 := data[j]
+// newGrid sorts the unique points and splits them into the cells of
+// the grid. It returns the non empty cells, sorted by their coordinates
+// the same way as the points.
+func newGrid(unique []DataPoint, layout *gridLayout) []gridCell {
+	sort.Slice(unique, datapointSortFn(unique))
 
-		s1 := 0
-		for i1 := range a1.Attrs {
-			s1 += a1.Attrs[i1]
-		}
+	cells := []gridCell{}
+	index := map[pointKey]int{}
 
-		s2 := 0
-		for i2 := range a2.Attrs {
-			s2 += a2.Attrs[i2]
+	// split to grid
+	for _, p := range unique {
+		coordinates := layout.cell(p.Attrs)
+		key := newPointKey(coordinates)
+
+		i, ok := index[key]
+		if !ok {
+			i = len(cells)
+			index[key] = i
+			cells = append(cells, gridCell{
+				coords: coordinates,
+				sum:    sumSlice(coordinates),
+			})
 		}
 
-		avg1 := float64(s1) / float64(len(a1.Attrs))
-		sd1 := 0.0
-		for i1 := range a1.Attrs {
-			sd1 += math.Pow(avg1-float64(a1.Attrs[i1]), 2.0)
-		}
+		cells[i].points = append(cells[i].points, p)
+		cells[i].count += p.Count
+	}
 
-		avg2 := float64(s2) / float64(len(a2.Attrs))
-		sd2 := 0.0
-		for i2 := range a2.Attrs {
-			sd2 += math.Pow(avg2-float64(a2.Attrs[i2]), 2.0)
-		}
+	sort.Slice(cells, func(i, j int) bool {
+		return sortsBefore(cells[i].coords, cells[j].coords)
+	})
 
-		if s1 > s2 {
-			return true
-		} else if s1 == s2 {
-			return sd1 < sd2
-		} else {
-			return false
+	return cells
+}
+
+// score calculates the domination score of every unique point
+// and assigns it to the rows that share its attributes
+func (dsc *DominationScoreCalculator) score(rows map[int]DataRow, stats *DataStats, unique []DataPoint, approximate bool, gridSize []int) (*Result, error) {
+	ps, timings, err := dsc.scorePoints(stats, unique, approximate, gridSize)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{
+		Scores:   make(map[int]int, len(rows)),
+		Stats:    stats,
+		Points:   len(unique),
+		GridSize: ps.gridSize,
+		Timings:  timings,
+	}
+
+	if approximate {
+		res.Bounds = make(map[int]Bounds, len(rows))
+	}
+
+	for id, n := range rows {
+		score, bounds := ps.get(n.Attrs)
+		res.Scores[id] = score
+		if approximate {
+			res.Bounds[id] = bounds
 		}
 	}
+
+	return res, nil
 }
 
-func (dsc *DominationScoreCalculator) Calc(dataReader DatasetReader, inputFile string, outputFile string, approximate bool, gridSize []int) {
-	total := time.Now()
+// pointScores holds the scores of the unique points of a dataset
+// by the key of their oriented attributes
+type pointScores struct {
+	o          *orientation
+	domination map[pointKey]int
 
-	t1 := time.Now()
-	rows, stats, unique := dataReader.ReadDataset(inputFile)
-	fmt.Printf("reading done in: %v\n", time.Since(t1))
+	// bounds is nil unless the scores are approximate
+	bounds map[pointKey]Bounds
 
-	t1 = time.Now()
-	sort.Slice(unique, datapointSortFn(unique))
+	// gridSize is the grid the scores were counted on
+	gridSize []int
+}
 
-	domination := map[string]int{}
-	grid := map[string][]DataPoint{}
+// get returns the score and the bounds of the score
+// of the row attributes attrs
+func (ps *pointScores) get(attrs []float64) (int, Bounds) {
+	key := newPointKey(ps.o.attrs(attrs))
+	return ps.domination[key], ps.bounds[key]
+}
 
-	// split to grid
-	for i := range unique {
+// scorePoints calculates the domination score of every unique point
+func (dsc *DominationScoreCalculator) scorePoints(stats *DataStats, unique []DataPoint, approximate bool, gridSize []int) (*pointScores, Timings, error) {
+	timings := Timings{}
 
-		c := unique[i]
+	if len(gridSize) > 0 {
+		err := checkGridSize(gridSize, len(stats.Max))
+		if err != nil {
+			return nil, timings, err
+		}
+	}
 
-		coordinates := translate(c.Attrs, stats, gridSize...)
-		key := getKey(coordinates)
+	o, err := dsc.orient(stats)
+	if err != nil {
+		return nil, timings, err
+	}
 
-		if _, ok := grid[key]; !ok {
-			grid[key] = []DataPoint{}
-		}
+	stats = o.dataStats()
+	unique = o.points(unique)
 
-		grid[key] = append(grid[key], c)
+	algorithm := dsc.exactAlgorithm(len(stats.Max))
+	if approximate || len(unique) == 0 {
+		algorithm = Grid
 	}
 
-	// get sorted coords
-	gridCoors := []DataPoint{}
-	for k := range grid {
+	if algorithm == DivideAndConquer {
+		t1 := time.Now()
+		domination := keyScores(unique, countDominated(unique))
+		timings.Main = time.Since(t1)
+		timings.Exact = timings.Main
 
-		coords := strings.Split(strings.TrimRight(k, "|"), "|")
+		dsc.logf("%vD %v done in: %v\n", len(stats.Max), algorithm, timings.Main)
+		return &pointScores{o: o, domination: domination, gridSize: gridSize}, timings, nil
+	}
 
-		a := []int{}
-		for i := range coords {
-			iv, _ := strconv.Atoi(coords[i])
-			a = append(a, iv)
+	if algorithm == Grid {
+		gridSize, err = dsc.gridSizeFor(stats, unique, approximate, gridSize)
+		if err != nil {
+			return nil, timings, err
 		}
+	}
 
-		gridCoors = append(gridCoors, DataPoint{
-			Attrs: a,
-		})
+	t1 := time.Now()
+	index := dsc.spatialIndex(algorithm, stats, unique, gridSize)
+	timings.Grid = time.Since(t1)
+	dsc.logf("creating %v index done in: %v\n", algorithm, timings.Grid)
+
+	// the grid scores a cell at a time, the other indexes a point
+	gi, ok := index.(*gridIndex)
+	if !ok {
+		t1 = time.Now()
+		domination := keyScores(unique, queryAll(index, unique, dsc.Workers))
+		timings.Main = time.Since(t1)
+		timings.Exact = timings.Main
+
+		dsc.logf("%vD %v done in: %v\n", len(stats.Max), algorithm, timings.Main)
+		return &pointScores{o: o, domination: domination, gridSize: gridSize}, timings, nil
+	}
 
+	domination := make(map[pointKey]int, len(unique))
+	var bounds map[pointKey]Bounds
+	if approximate {
+		bounds = make(map[pointKey]Bounds, len(unique))
 	}
-	fmt.Printf("creating grid done in: %v\n", time.Since(t1))
 
 	// main loop
 	mainCalc := time.Now()
+
+	workers := dsc.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(gi.cells) {
+		workers = len(gi.cells)
+	}
+
+	// cells are handed out one at a time, since the cells at the
+	// start of grid compare against many more cells than the
+	// ones at the end. every unique point belongs to exactly one
+	// cell, so each worker owns the scores it writes and the partial
+	// maps can be merged without conflicts.
+	cells := make(chan int, workers)
+	partials := make([]cellWorker, workers)
+
+	var progress sync.Mutex
+	done := 0
 	t1 = time.Now()
-	sort.Slice(gridCoors, datapointSortFn(gridCoors))
 
-	var la time.Duration
-	var lb time.Duration
-	var lc time.Duration
+	var wg sync.WaitGroup
+	for w := range partials {
+		partials[w].domination = map[pointKey]int{}
+		if approximate {
+			partials[w].bounds = map[pointKey]Bounds{}
+		}
 
-	for i := range gridCoors {
+		wg.Add(1)
+		go func(cw *cellWorker) {
+			defer wg.Done()
+			for i := range cells {
+				cw.scoreCell(i, gi, approximate)
+
+				progress.Lock()
+				done++
+				if done%1000 == 0 {
+					dsc.logf("%v (%v of %v)\tworkers:%v\tapprx:%v\n", time.Since(t1), done, len(gi.cells), workers, approximate)
+					t1 = time.Now()
+				}
+				progress.Unlock()
+			}
+		}(&partials[w])
+	}
 
-		ik := getKey(gridCoors[i].Attrs)
-		point := gridCoors[i].Attrs
+	for i := range gi.cells {
+		cells <- i
+	}
+	close(cells)
+	wg.Wait()
 
-		sum := sumSlice(point)
+	for _, cw := range partials {
+		for k, v := range cw.domination {
+			domination[k] = v
+		}
+		for k, v := range cw.bounds {
+			bounds[k] = v
+		}
+		timings.Cells += cw.la
+		timings.Approximate += cw.lb
+		timings.Exact += cw.lc
+	}
+	timings.Main = time.Since(mainCalc)
 
-		baseScore := 0
-		later := []DataPoint{}
+	dsc.logf("cells: %v\tworkers: %v\t%v\t%v\t%v\n", len(gi.cells), workers, timings.Cells, timings.Approximate, timings.Exact)
+	dsc.logf("main calc done in: %v\n", timings.Main)
 
-		l1 := time.Now()
+	return &pointScores{o: o, domination: domination, bounds: bounds, gridSize: gridSize}, timings, nil
+}
 
-		for _, j := range gridCoors[i:] {
-			if sumSlice(j.Attrs) > sum {
-				continue
-			}
+// spatialIndex builds the index of algorithm, Grid or KDTree, over the
+// oriented unique points. The grid has gridSize cells per attribute.
+func (dsc *DominationScoreCalculator) spatialIndex(algorithm Algorithm, stats *DataStats, unique []DataPoint, gridSize []int) SpatialIndex {
+	if algorithm == KDTree {
+		return NewKDTree(unique)
+	}
+	return newGridIndex(unique, newGridLayout(stats, gridSize, dsc.GridMode))
+}
 
-			jk := getKey(j.Attrs)
-			point_to_compare_with := j.Attrs
+// keyScores maps the key of every one of points to its score in scores
+func keyScores(points []DataPoint, scores []int) map[pointKey]int {
+	res := make(map[pointKey]int, len(points))
+	for i, p := range points {
+		res[newPointKey(p.Attrs)] = scores[i]
+	}
+	return res
+}
 
-			if a_less_b(point_to_compare_with, point) {
-				for _, v := range grid[jk] {
-					baseScore += v.Count
-				}
-			} else if a_less_or_equal_b(point_to_compare_with, point) {
-				later = append(later, grid[jk]...)
-			}
-		}
-		la += time.Since(l1)
+// cellWorker holds the scores and the timings of the grid cells
+// processed by a single goroutine of the main loop
+type cellWorker struct {
+	domination map[pointKey]int
+	bounds     map[pointKey]Bounds
 
-		for _, n := range grid[ik] {
+	la time.Duration
+	lb time.Duration
+	lc time.Duration
+}
 
-			nodeScore := baseScore
+// scoreCell calculates the domination score of every point in the
+// grid cell gi.cells[i]
+func (cw *cellWorker) scoreCell(i int, gi *gridIndex, approximate bool) {
 
-			if approximate {
-				l2 := time.Now()
-				agrCellItems := 0
-				for _, l := range later {
-					agrCellItems += l.Count
-				}
+	l1 := time.Now()
+	baseScore, later := scanCell(i, gi)
+	cw.la += time.Since(l1)
 
-				apprx := translateApprx(n.Attrs, stats, gridSize...)
-				approximateScore := float64(agrCellItems) * apprx
+	agrCellItems := 0
+	if approximate {
+		for _, l := range later {
+			agrCellItems += l.Count
+		}
+	}
 
-				nodeScore += int(approximateScore)
-				lb += time.Since(l2)
+	for _, n := range gi.cells[i].points {
 
-			} else {
+		nodeScore := baseScore
+		key := newPointKey(n.Attrs)
 
-				l3 := time.Now()
-				for _, l := range later {
-					if a_dominates_b(n.Attrs, l.Attrs) {
-						nodeScore += l.Count
-					}
-				}
-				lc += time.Since(l3)
+		if approximate {
+			l2 := time.Now()
+			// the points of later include n and the points equal
+			// to it, which n cannot dominate
+			others := agrCellItems - n.Count
+
+			apprx := gi.layout.fraction(n.Attrs)
+			approximateScore := float64(others) * apprx
+
+			nodeScore += int(approximateScore)
+
+			cw.bounds[key] = Bounds{
+				Lower: baseScore,
+				Upper: baseScore + others,
 			}
+			cw.lb += time.Since(l2)
 
-			domination[getKey(n.Attrs)] = nodeScore
-		}
+		} else {
 
-		if i%1000 == 0 && i > 0 {
-			fmt.Printf("%v (%v of %v)\t%v\t%v\t%v\tapprx:%v\n", time.Since(t1), i+1, len(gridCoors), la, lb, lc, approximate)
-			t1 = time.Now()
+			l3 := time.Now()
+			nodeScore += partialScore(n, later)
+			cw.lc += time.Since(l3)
 		}
-	}
-	fmt.Printf("main calc done in: %v\n", time.Since(mainCalc))
-	t1 = time.Now()
 
-	// write outfile
-	fd, err := os.Create(outputFile)
-	if err != nil {
-		fd, _ = os.Create("dom_out_new.txt")
+		cw.domination[key] = nodeScore
 	}
+}
 
-	fd.WriteString("id\tdom\n")
-	for i := range rows {
-		n := rows[i]
-		k := getKey(n.Attrs)
-		fd.WriteString(fmt.Sprintf("%v\t%v\n", n.ID, domination[k]))
-	}
-	fd.Close()
+// scanCell compares the grid cell gi.cells[i] with the cells sorted
+// after it. It returns the number of points in the cells that are lower
+// in every coordinate, which are dominated by every point of the cell,
+// and the points of the cells that are lower or equal, which have to
+// be compared with each point of the cell one by one.
+func scanCell(i int, gi *gridIndex) (int, []DataPoint) {
+	later := []DataPoint{}
+	baseScore := gi.below(i, gi.cells[i].coords, func(j int) {
+		later = append(later, gi.cells[j].points...)
+	})
+	return baseScore, later
+}
 
-	fmt.Printf("write results to file done in: %v\n", time.Since(t1))
-	fmt.Println(time.Since(total))
+// partialScore returns the number of points in later that n dominates
+func partialScore(n DataPoint, later []DataPoint) int {
+	score := 0
+	for _, l := range later {
+		if a_dominates_b(n.Attrs, l.Attrs) {
+			score += l.Count
+		}
+	}
+	return score
 }
@@ -0,0 +1,486 @@
+package domination
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func rowsOf(attrs ...[]float64) []DataRow {
+	rows := make([]DataRow, len(attrs))
+	for i := range attrs {
+		rows[i] = DataRow{
+			ID:    i + 1,
+			Name:  fmt.Sprintf("n%v", i+1),
+			Attrs: attrs[i],
+		}
+	}
+	return rows
+}
+
+// randomRows returns n rows with d attributes in [0, max). Small
+// values of max produce many duplicates and ties. With correlated set
+// the attributes of a row are drawn around a common per row mean.
+func randomRows(r *rand.Rand, n, d, max int, correlated bool) []DataRow {
+	rows := make([]DataRow, n)
+	for i := range rows {
+		attrs := make([]float64, d)
+		mean := r.Intn(max)
+		for j := range attrs {
+			if correlated {
+				v := mean + r.Intn(max/4+1) - max/8
+				if v < 0 {
+					v = 0
+				}
+				attrs[j] = float64(v)
+			} else {
+				attrs[j] = float64(r.Intn(max))
+			}
+		}
+		rows[i] = DataRow{ID: i, Attrs: attrs}
+	}
+	return rows
+}
+
+func gridOf(d, size int) []int {
+	res := make([]int, d)
+	for i := range res {
+		res[i] = size
+	}
+	return res
+}
+
+func assertScores(t *testing.T, got, want map[int]int) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %v scores, want %v", len(got), len(want))
+	}
+
+	for id, w := range want {
+		if g, ok := got[id]; !ok || g != w {
+			t.Errorf("id %v: got score %v, want %v", id, g, w)
+		}
+	}
+}
+
+func TestDominates(t *testing.T) {
+	tests := []struct {
+		a, b []float64
+		want bool
+	}{
+		{[]float64{2, 2}, []float64{1, 1}, true},
+		{[]float64{2, 1}, []float64{1, 1}, true},
+		{[]float64{1, 1}, []float64{1, 1}, false},
+		{[]float64{1, 1}, []float64{2, 2}, false},
+		{[]float64{3, 1}, []float64{1, 3}, false},
+		{[]float64{5, 5, 5, 5}, []float64{5, 5, 5, 4}, true},
+		{[]float64{5, 5, 5, 4}, []float64{5, 5, 5, 5}, false},
+	}
+
+	for _, tt := range tests {
+		if got := a_dominates_b(tt.a, tt.b); got != tt.want {
+			t.Errorf("a_dominates_b(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     []DataRow
+		gridSize []int
+		want     map[int]int
+	}{
+		{
+			name:     "chain",
+			rows:     rowsOf([]float64{1, 1}, []float64{2, 2}, []float64{3, 3}),
+			gridSize: []int{2, 2},
+			want:     map[int]int{1: 0, 2: 1, 3: 2},
+		},
+		{
+			name:     "duplicates do not dominate each other",
+			rows:     rowsOf([]float64{2, 2}, []float64{2, 2}, []float64{1, 1}),
+			gridSize: []int{2, 2},
+			want:     map[int]int{1: 1, 2: 1, 3: 0},
+		},
+		{
+			name:     "ties on one attribute",
+			rows:     rowsOf([]float64{2, 1}, []float64{2, 2}, []float64{1, 2}),
+			gridSize: []int{3, 3},
+			want:     map[int]int{1: 0, 2: 2, 3: 0},
+		},
+		{
+			name:     "incomparable",
+			rows:     rowsOf([]float64{1, 3}, []float64{3, 1}),
+			gridSize: []int{2, 2},
+			want:     map[int]int{1: 0, 2: 0},
+		},
+		{
+			name: "full cells and partial cells",
+			rows: rowsOf(
+				[]float64{0, 0, 0}, []float64{1, 1, 1}, []float64{1, 1, 1},
+				[]float64{5, 5, 5}, []float64{5, 6, 5}, []float64{9, 9, 9},
+				[]float64{9, 0, 9}, []float64{10, 10, 10},
+			),
+			gridSize: []int{2, 2, 2},
+			want:     map[int]int{1: 0, 2: 1, 3: 1, 4: 3, 5: 4, 6: 6, 7: 1, 8: 7},
+		},
+		{
+			name:     "single row",
+			rows:     rowsOf([]float64{4, 2, 7, 1}),
+			gridSize: []int{25, 25, 25, 25},
+			want:     map[int]int{1: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertScores(t, ReferenceScores(tt.rows), tt.want)
+
+			for _, algorithm := range []Algorithm{Grid, DivideAndConquer} {
+				for _, workers := range []int{1, 4} {
+					dsc := &DominationScoreCalculator{Workers: workers, Algorithm: algorithm}
+
+					res, err := dsc.Score(tt.rows, false, tt.gridSize)
+					if err != nil {
+						t.Fatal(err)
+					}
+					assertScores(t, res.Scores, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// TestFloatAttributes checks that fractional values, like the P-index
+// of the AMiner dataset, are not collapsed into ties
+func TestFloatAttributes(t *testing.T) {
+	rows := rowsOf(
+		[]float64{3, 12.25}, []float64{3, 12.5}, []float64{3, 12.75},
+		[]float64{3, 12.5}, []float64{-0.5, 0}, []float64{-0.25, -0.0},
+	)
+	want := map[int]int{1: 2, 2: 3, 3: 5, 4: 3, 5: 0, 6: 1}
+
+	assertScores(t, ReferenceScores(rows), want)
+
+	dsc := &DominationScoreCalculator{}
+	for _, size := range []int{1, 2, 10} {
+		res, err := dsc.Score(rows, false, []int{size, size})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertScores(t, res.Scores, want)
+	}
+}
+
+func TestScoreErrors(t *testing.T) {
+	dsc := &DominationScoreCalculator{}
+
+	_, err := dsc.Score(rowsOf([]float64{1, 2}, []float64{1, 2, 3}), false, []int{2, 2, 2})
+	if err == nil {
+		t.Error("expected an error for rows with different number of attributes")
+	}
+
+	_, err = dsc.Score(rowsOf([]float64{1, 2, 3}), false, []int{2, 2})
+	if err == nil {
+		t.Error("expected an error for a grid size with fewer dimensions than the rows")
+	}
+
+	_, err = dsc.Score(rowsOf([]float64{1, 2}, []float64{2, 1}), true, []int{2, 0})
+	if err == nil {
+		t.Error("expected an error for a grid size with a zero entry")
+	}
+
+	for _, algorithm := range []Algorithm{Grid, DivideAndConquer} {
+		_, err = (&DominationScoreCalculator{Algorithm: algorithm}).Score(rowsOf([]float64{1, 2}), false, []int{2, 2, 2})
+		if err == nil {
+			t.Errorf("%v: expected an error for a grid size with more dimensions than the rows", algorithm)
+		}
+	}
+
+	_, err = dsc.Score(nil, false, []int{2, 2})
+	if err != nil {
+		t.Errorf("got %v for an empty dataset with a grid size", err)
+	}
+
+	res, err := dsc.Score(nil, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Scores) != 0 {
+		t.Errorf("got %v scores for an empty dataset", len(res.Scores))
+	}
+}
+
+func TestCalc(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	rows := randomRows(r, 500, 4, 20, false)
+	for i := range rows {
+		rows[i].Attrs[3] += float64(r.Intn(4)) / 4
+	}
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "nodes.csv")
+	output := filepath.Join(dir, "domination.txt")
+
+	sb := strings.Builder{}
+	sb.WriteString("id,name,pc,cn,hi,pi\n")
+	for _, row := range rows {
+		sb.WriteString(fmt.Sprintf("%v,n%v,%v,%v,%v,%v\n", row.ID, row.ID, row.Attrs[0], row.Attrs[1], row.Attrs[2], row.Attrs[3]))
+	}
+	err := os.WriteFile(input, []byte(sb.String()), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	columns, err := AminerColumns(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dsc := &DominationScoreCalculator{Workers: 2}
+	err = dsc.Calc(&CSVDatasetReader{Columns: columns}, input, output, false, []int{5, 5, 5, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Builder{}
+	err = WriteScores(&want, ReferenceScores(rows))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != want.String() {
+		t.Errorf("output file differs from the reference scores")
+	}
+}
+
+// referenceConfig is a way of scoring a dataset that
+// TestMatchesReference compares to the reference scores
+type referenceConfig struct {
+	name string
+	dsc  DominationScoreCalculator
+
+	// query is "score", "approximate", "top-k", "skyline" or "index"
+	query string
+
+	// gridSizes are the cells per attribute to run with,
+	// 0 for an automatic grid size
+	gridSizes []int
+}
+
+var referenceConfigs = []referenceConfig{
+	{name: "grid", dsc: DominationScoreCalculator{Workers: 3, Algorithm: Grid}, query: "score", gridSizes: []int{1, 2, 3, 7, 25, 0}},
+	{name: "grid 1 worker", dsc: DominationScoreCalculator{Workers: 1, Algorithm: Grid}, query: "score", gridSizes: []int{3, 25}},
+	{name: "quantile grid", dsc: DominationScoreCalculator{Workers: 2, Algorithm: Grid, GridMode: Quantile}, query: "score", gridSizes: []int{1, 3, 25, 0}},
+	{name: "sampled auto grid", dsc: DominationScoreCalculator{Workers: 2, Algorithm: Grid, GridSample: 50}, query: "score", gridSizes: []int{0}},
+	{name: "divide and conquer", dsc: DominationScoreCalculator{Algorithm: DivideAndConquer}, query: "score", gridSizes: []int{3}},
+	{name: "k-d tree", dsc: DominationScoreCalculator{Workers: 3, Algorithm: KDTree}, query: "score", gridSizes: []int{3}},
+	{name: "auto", dsc: DominationScoreCalculator{}, query: "score", gridSizes: []int{0}},
+	{name: "approximate", dsc: DominationScoreCalculator{Workers: 2}, query: "approximate", gridSizes: []int{1, 4, 20}},
+	{name: "quantile approximate", dsc: DominationScoreCalculator{Workers: 2, GridMode: Quantile}, query: "approximate", gridSizes: []int{1, 4, 20}},
+	{name: "top-k", dsc: DominationScoreCalculator{}, query: "top-k", gridSizes: []int{1, 3, 10, 0}},
+	{name: "quantile top-k", dsc: DominationScoreCalculator{GridMode: Quantile}, query: "top-k", gridSizes: []int{3, 25}},
+	{name: "skyline", dsc: DominationScoreCalculator{}, query: "skyline", gridSizes: []int{1, 3, 10, 0}},
+	{name: "quantile skyline", dsc: DominationScoreCalculator{GridMode: Quantile}, query: "skyline", gridSizes: []int{3, 25}},
+	{name: "index", dsc: DominationScoreCalculator{Workers: 2}, query: "index", gridSizes: []int{5, 0}},
+	{name: "quantile index", dsc: DominationScoreCalculator{Workers: 2, GridMode: Quantile}, query: "index", gridSizes: []int{4}},
+}
+
+// TestMatchesReference runs every one of referenceConfigs on random
+// datasets, for a range of dimensions, value ranges and directions, and
+// compares the results to ReferenceScores
+func TestMatchesReference(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for d := 1; d <= 5; d++ {
+		for _, max := range []int{4, 16, 1000} {
+			for _, correlated := range []bool{false, true} {
+				for _, minimize := range []bool{false, true} {
+					rows := randomRows(r, 1+r.Intn(300), d, max, correlated)
+					// a long tail on the first attribute
+					for i := range rows {
+						if r.Intn(20) == 0 {
+							rows[i].Attrs[0] *= 50
+						}
+					}
+
+					var directions []Direction
+					if minimize {
+						directions = make([]Direction, d)
+						directions[d-1] = Minimize
+					}
+
+					for _, c := range referenceConfigs {
+						for _, size := range c.gridSizes {
+							name := fmt.Sprintf("%v/%vD max %v correlated %v directions %v/grid %v", c.name, d, max, correlated, directions, size)
+							t.Run(name, func(t *testing.T) {
+								dsc := c.dsc
+								dsc.Directions = directions
+
+								var gridSize []int
+								if size > 0 {
+									gridSize = gridOf(d, size)
+								}
+
+								checkReference(t, r, &dsc, c.query, rows, gridSize)
+							})
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// checkReference runs query on rows with dsc and compares the result
+// to the reference scores
+func checkReference(t *testing.T, r *rand.Rand, dsc *DominationScoreCalculator, query string, rows []DataRow, gridSize []int) {
+	want := ReferenceScores(rows, dsc.Directions...)
+
+	switch query {
+	case "score":
+		res, err := dsc.Score(rows, false, gridSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertScores(t, res.Scores, want)
+		if res.Bounds != nil {
+			t.Errorf("got bounds in exact mode")
+		}
+
+	case "approximate":
+		res, err := dsc.Score(rows, true, gridSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Bounds) != len(rows) {
+			t.Fatalf("got bounds for %v rows, want %v", len(res.Bounds), len(rows))
+		}
+		for id, score := range want {
+			if b := res.Bounds[id]; score < b.Lower || score > b.Upper {
+				t.Errorf("row %v scores %v, outside its bounds %v", id, score, b)
+			}
+		}
+		if acc := NewAccuracy(res.Scores, res.Bounds); acc.Rows != len(rows) || acc.Outside != 0 {
+			t.Errorf("got accuracy %+v", acc)
+		}
+
+	case "top-k":
+		for _, k := range []int{0, 1, 5, 50, 1000} {
+			got, err := dsc.TopK(rows, k, gridSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := referenceTopK(rows, k, dsc.Directions...); !reflect.DeepEqual(got, want) {
+				t.Errorf("top-%v: got %v, want %v", k, got, want)
+			}
+		}
+
+	case "skyline":
+		sky, err := dsc.SkylineRows(rows, gridSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := []int{}
+		for _, row := range sky.Rows {
+			ids = append(ids, row.ID)
+		}
+		if got, want := fmt.Sprint(ids), fmt.Sprint(referenceSkyline(rows, dsc.Directions...)); got != want {
+			t.Errorf("got skyline %v, want %v", got, want)
+		}
+
+	case "index":
+		checkIndex(t, r, dsc, rows, gridSize)
+
+	default:
+		t.Fatalf("unknown query %q", query)
+	}
+}
+
+// checkIndex builds an Index over the first rows, inserts the rest and
+// then inserts, deletes and updates random rows, some of them past the
+// range the grid was built with, comparing the scores to the reference
+// after every step
+func checkIndex(t *testing.T, r *rand.Rand, dsc *DominationScoreCalculator, rows []DataRow, gridSize []int) {
+	d := len(rows[0].Attrs)
+	built := 1 + len(rows)/2
+
+	ix, err := dsc.NewIndex(rows[:built], gridSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ix.Insert(rows[built:]...); err != nil {
+		t.Fatal(err)
+	}
+
+	current := map[int]DataRow{}
+	for _, row := range rows {
+		current[row.ID] = row
+	}
+	nextID := len(rows)
+
+	for step := 0; step < 5; step++ {
+		inserted := randomRows(r, r.Intn(10), d, 2000, false)
+		for i := range inserted {
+			inserted[i].ID = nextID
+			current[nextID] = inserted[i]
+			nextID++
+		}
+
+		ids := []int{}
+		for id := range current {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		r.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+
+		deleted := ids[:r.Intn(len(ids)/2+1)]
+		updated := []DataRow{}
+		for _, id := range ids[len(deleted) : len(deleted)+r.Intn(len(ids)-len(deleted)+1)] {
+			row := randomRows(r, 1, d, 1000, false)[0]
+			row.ID = id
+			updated = append(updated, row)
+		}
+
+		if err := ix.Insert(inserted...); err != nil {
+			t.Fatal(err)
+		}
+		if err := ix.Delete(deleted...); err != nil {
+			t.Fatal(err)
+		}
+		if err := ix.Update(updated...); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, id := range deleted {
+			delete(current, id)
+		}
+		for _, row := range updated {
+			current[row.ID] = row
+		}
+
+		rows := make([]DataRow, 0, len(current))
+		for _, row := range current {
+			rows = append(rows, row)
+		}
+		if ix.Len() != len(rows) {
+			t.Fatalf("step %v: got %v rows, want %v", step, ix.Len(), len(rows))
+		}
+		assertScores(t, ix.Scores(), ReferenceScores(rows, dsc.Directions...))
+		if t.Failed() {
+			t.Fatalf("step %v differs from the reference", step)
+		}
+	}
+}
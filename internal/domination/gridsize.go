@@ -0,0 +1,222 @@
+package domination
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// GridSize is the number of grid cells along every attribute. An empty
+// GridSize, written as "auto" in the settings.json files, leaves the
+// calculator to pick one from the data.
+type GridSize []int
+
+// MarshalJSON writes an empty grid size as "auto"
+func (g GridSize) MarshalJSON() ([]byte, error) {
+	if len(g) == 0 {
+		return json.Marshal("auto")
+	}
+	return json.Marshal([]int(g))
+}
+
+// UnmarshalJSON reads a list of cell counts or "auto"
+func (g *GridSize) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var name string
+		err := json.Unmarshal(b, &name)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(name, "auto") {
+			return fmt.Errorf("unknown grid size %q, should be a list of cell counts or auto", name)
+		}
+		*g = nil
+		return nil
+	}
+
+	var sizes []int
+	err := json.Unmarshal(b, &sizes)
+	if err != nil {
+		return err
+	}
+	*g = sizes
+	return nil
+}
+
+// checkGridSize returns an error unless gridSize has exactly one
+// cell count, of at least 1, for every one of the dimensions. Any
+// grid size fits an empty dataset, which has no dimensions.
+func checkGridSize(gridSize []int, dimensions int) error {
+	if dimensions == 0 {
+		return nil
+	}
+	if len(gridSize) != dimensions {
+		return fmt.Errorf("grid size has %v dimensions, rows have %v attributes", len(gridSize), dimensions)
+	}
+	for i, s := range gridSize {
+		if s < 1 {
+			return fmt.Errorf("grid size of attribute %v is %v, should be at least 1", i, s)
+		}
+	}
+	return nil
+}
+
+// autoCellsPerRoot is the number of non empty cells an automatic grid
+// aims for per square root of the unique points. Every cell is compared
+// with every other one, which grows with the square of the cells, and
+// the points of the partially dominated cells one by one, which grows
+// with the points per cell; on the generated datasets the two balance
+// at about 10√n cells.
+const autoCellsPerRoot = 10
+
+// autoGridFactors are the multiples of the cells an automatic
+// grid aims for that are timed on a sample, see GridSample
+var autoGridFactors = []float64{0.5, 1, 2}
+
+// autoGridMargin is how much faster on the sample another factor has
+// to be to replace 1, since timings of small samples are noisy
+const autoGridMargin = 0.75
+
+// gridSizeFor returns gridSize, or the automatic grid size of the
+// unique points when gridSize is empty. stats and unique are oriented.
+func (dsc *DominationScoreCalculator) gridSizeFor(stats *DataStats, unique []DataPoint, approximate bool, gridSize []int) ([]int, error) {
+	if len(gridSize) > 0 {
+		return gridSize, checkGridSize(gridSize, len(stats.Max))
+	}
+
+	t1 := time.Now()
+
+	factor := 1.0
+	if dsc.GridSample > 0 && dsc.GridSample < len(unique) {
+		var err error
+		factor, err = dsc.timeGridFactors(stats, samplePoints(unique, dsc.GridSample), approximate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := autoGridSize(stats, unique, dsc.GridMode, factor)
+	dsc.logf("auto grid size %v done in: %v\n", res, time.Since(t1))
+
+	return res, nil
+}
+
+// autoGridSize returns the grid size with the same number of cells
+// along every attribute, but for the attributes with fewer distinct
+// values, that splits the unique points into the most non empty cells
+// not above factor times autoCellsPerRoot√n
+func autoGridSize(stats *DataStats, unique []DataPoint, mode GridMode, factor float64) []int {
+	target := int(factor * autoCellsPerRoot * math.Sqrt(float64(len(unique))))
+
+	limits := make([]int, len(stats.Max))
+	most := 1
+	for i := range limits {
+		limits[i] = cellLimit(stats, i, len(unique))
+		if limits[i] > most {
+			most = limits[i]
+		}
+	}
+
+	sizes := func(cells int) []int {
+		res := make([]int, len(limits))
+		for i, l := range limits {
+			res[i] = cells
+			if l < cells {
+				res[i] = l
+			}
+		}
+		return res
+	}
+
+	// the non empty cells grow with the cells per attribute,
+	// so the most cells within the target are searched for
+	lo, hi := 1, most
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if nonEmptyCells(stats, unique, sizes(mid), mode) <= target {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+
+	return sizes(lo)
+}
+
+// cellLimit returns the most cells worth having along attribute i,
+// the number of distinct values it takes
+func cellLimit(stats *DataStats, i int, points int) int {
+	limit := points
+	if i < len(stats.Histogram) && stats.Histogram[i] != nil {
+		limit = len(stats.Histogram[i])
+	}
+	if stats.Max[i] == stats.Min[i] || limit < 1 {
+		return 1
+	}
+	return limit
+}
+
+// nonEmptyCells returns the number of cells of the grid
+// of gridSize the unique points fall in
+func nonEmptyCells(stats *DataStats, unique []DataPoint, gridSize []int, mode GridMode) int {
+	layout := newGridLayout(stats, gridSize, mode)
+
+	cells := map[pointKey]struct{}{}
+	for _, p := range unique {
+		cells[newPointKey(layout.cell(p.Attrs))] = struct{}{}
+	}
+	return len(cells)
+}
+
+// samplePoints returns n of the points, evenly spread over them
+func samplePoints(points []DataPoint, n int) []DataPoint {
+	res := make([]DataPoint, n)
+	for i := range res {
+		res[i] = points[i*len(points)/n]
+	}
+	return res
+}
+
+// timeGridFactors scores the sample with the automatic grid of every
+// one of autoGridFactors and returns the factor that was the fastest,
+// by autoGridMargin for any factor but 1
+func (dsc *DominationScoreCalculator) timeGridFactors(stats *DataStats, sample []DataPoint, approximate bool) (float64, error) {
+	// stats and sample are already oriented
+	sub := &DominationScoreCalculator{
+		Workers:   dsc.Workers,
+		Algorithm: Grid,
+		GridMode:  dsc.GridMode,
+	}
+
+	best := 1.0
+	fastest := time.Duration(math.MaxInt64)
+	timed := map[string]bool{}
+
+	for _, f := range autoGridFactors {
+		gridSize := autoGridSize(stats, sample, dsc.GridMode, f)
+		key := fmt.Sprint(gridSize)
+		if timed[key] {
+			continue
+		}
+		timed[key] = true
+
+		t1 := time.Now()
+		_, _, err := sub.scorePoints(stats, append([]DataPoint{}, sample...), approximate, gridSize)
+		if err != nil {
+			return 0, err
+		}
+		d := time.Since(t1)
+
+		dsc.logf("grid size %v on %v sample points done in: %v\n", gridSize, len(sample), d)
+		if f != 1 {
+			d = time.Duration(float64(d) / autoGridMargin)
+		}
+		if d < fastest {
+			best, fastest = f, d
+		}
+	}
+
+	return best, nil
+}
@@ -0,0 +1,113 @@
+package domination
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+func TestGridSizeJSON(t *testing.T) {
+	for in, want := range map[string]string{
+		`[10, 25]`: "[10 25]",
+		`"auto"`:   "[]",
+		`"AUTO"`:   "[]",
+		`null`:     "[]",
+	} {
+		var g GridSize
+		if err := json.Unmarshal([]byte(in), &g); err != nil {
+			t.Errorf("%v: %v", in, err)
+			continue
+		}
+		if fmt.Sprint(g) != want {
+			t.Errorf("%v: got %v, want %v", in, g, want)
+		}
+	}
+
+	for _, in := range []string{`"large"`, `{"size": 10}`} {
+		var g GridSize
+		if err := json.Unmarshal([]byte(in), &g); err == nil {
+			t.Errorf("%v: expected an error", in)
+		}
+	}
+
+	for g, want := range map[string]GridSize{`"auto"`: nil, `[3,4]`: {3, 4}} {
+		b, err := json.Marshal(want)
+		if err != nil || string(b) != g {
+			t.Errorf("got %s, %v, want %v", b, err, g)
+		}
+	}
+}
+
+func TestAutoGridSize(t *testing.T) {
+	r := rand.New(rand.NewSource(9))
+
+	for _, mode := range []GridMode{EqualWidth, Quantile} {
+		for d := 2; d <= 5; d++ {
+			rows := randomRows(r, 2000, d, 1000, false)
+			// a single value on the last attribute
+			for i := range rows {
+				rows[i].Attrs[d-1] = 7
+			}
+			_, stats, unique, err := readRows(&sliceIterator{rows: rows}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := autoGridSize(stats, unique, mode, 1)
+			name := fmt.Sprintf("%v %vD", mode, d)
+			if len(got) != d || got[d-1] != 1 {
+				t.Fatalf("%v: got grid size %v", name, got)
+			}
+
+			target := int(autoCellsPerRoot * math.Sqrt(float64(len(unique))))
+			if cells := nonEmptyCells(stats, unique, got, mode); cells > target {
+				t.Errorf("%v: grid size %v has %v cells, more than %v", name, got, cells, target)
+			}
+
+			larger := append([]int{}, got...)
+			for i := 0; i < d-1; i++ {
+				larger[i]++
+			}
+			if cells := nonEmptyCells(stats, unique, larger, mode); cells <= target {
+				t.Errorf("%v: grid size %v has %v cells, larger %v has %v within %v", name, got, nonEmptyCells(stats, unique, got, mode), larger, cells, target)
+			}
+		}
+	}
+}
+
+func TestCalcAutoGridSize(t *testing.T) {
+	input := writeFile(t, "1\t1\t2\n2\t3\t4\n3\t4\t0\n4\t2\t2\n")
+	cr := &CSVDatasetReader{
+		Columns: ColumnMapping{
+			Delimiter: "\t",
+			ID:        ColumnIndex(0),
+			Attrs:     []Column{ColumnIndex(1), ColumnIndex(2)},
+		},
+	}
+	dsc := &DominationScoreCalculator{Workers: 1}
+
+	for _, streaming := range []bool{false, true} {
+		output := filepath.Join(t.TempDir(), "domination.txt")
+
+		var err error
+		if streaming {
+			err = dsc.CalcStream(cr, input, output, true, nil)
+		} else {
+			err = dsc.Calc(cr, input, output, true, nil)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m, err := ReadManifest(manifestFile(output))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !m.AutoGridSize || len(m.GridSize) != 2 {
+			t.Errorf("streaming %v: got grid size %v, auto %v", streaming, m.GridSize, m.AutoGridSize)
+		}
+	}
+}
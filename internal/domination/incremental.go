@@ -0,0 +1,296 @@
+package domination
+
+import (
+	"fmt"
+	"math"
+)
+
+// Index keeps the domination scores of a dataset up to date as rows are
+// inserted, deleted and updated, without scoring the whole dataset
+// again. Every change only visits the grid cells that can hold points
+// dominating, or dominated by, the changed point: inserting a point
+// raises the score of each point dominating it and scores the new point
+// against the cells below it, deleting a point lowers the scores of its
+// dominators.
+//
+// The grid keeps the cell width of the dataset the index was built
+// with. Rows outside of its range fall in cells beyond the original
+// ones, which keeps the scores exact but makes the cells uneven as the
+// data drifts away, so a full Score is worth running once in a while.
+type Index struct {
+	o          *orientation
+	dimensions int
+	rows       map[int]DataRow
+
+	// grid holds the unique points, in the orientation where larger
+	// is better on every attribute, and cells maps the coordinates of
+	// every one of its cells to its position in grid.cells
+	grid  *gridIndex
+	cells map[pointKey]int
+
+	// scores holds the score of every unique point
+	scores map[pointKey]int
+}
+
+// NewIndex scores rows and returns an Index to keep the scores up to
+// date with. The grid is built over rows, which should not be empty.
+func (dsc *DominationScoreCalculator) NewIndex(rows []DataRow, gridSize []int) (*Index, error) {
+	r, stats, unique, err := readRows(&sliceIterator{rows: rows}, gridSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(r) == 0 {
+		return nil, fmt.Errorf("no rows to build the index with")
+	}
+
+	o, err := dsc.orient(stats)
+	if err != nil {
+		return nil, err
+	}
+
+	gridSize, err = dsc.gridSizeFor(o.dataStats(), o.points(unique), false, gridSize)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := dsc.score(r, stats, unique, false, gridSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ix := &Index{
+		o:          o,
+		dimensions: len(stats.Max),
+		rows:       r,
+		grid:       newGridIndex(o.points(unique), newGridLayout(o.dataStats(), gridSize, dsc.GridMode)),
+		cells:      map[pointKey]int{},
+		scores:     make(map[pointKey]int, len(unique)),
+	}
+
+	for i, c := range ix.grid.cells {
+		ix.cells[newPointKey(c.coords)] = i
+	}
+	for id, row := range r {
+		ix.scores[newPointKey(o.attrs(row.Attrs))] = res.Scores[id]
+	}
+
+	return ix, nil
+}
+
+// cell returns the grid cell of the point attrs,
+// creating it when create is true and it is missing
+func (ix *Index) cell(attrs []float64, create bool) *gridCell {
+	coords := ix.grid.layout.cell(attrs)
+	key := newPointKey(coords)
+
+	i, ok := ix.cells[key]
+	if !ok {
+		if !create {
+			return nil
+		}
+
+		i = len(ix.grid.cells)
+		ix.cells[key] = i
+		ix.grid.cells = append(ix.grid.cells, gridCell{
+			coords: coords,
+			sum:    sumSlice(coords),
+		})
+	}
+	return &ix.grid.cells[i]
+}
+
+// Len returns the number of rows in the index
+func (ix *Index) Len() int {
+	return len(ix.rows)
+}
+
+// Score returns the domination score of the row id
+// and false when there is no such row
+func (ix *Index) Score(id int) (int, bool) {
+	row, ok := ix.rows[id]
+	if !ok {
+		return 0, false
+	}
+	return ix.scores[newPointKey(ix.o.attrs(row.Attrs))], true
+}
+
+// Scores returns the domination score of every row
+func (ix *Index) Scores() map[int]int {
+	res := make(map[int]int, len(ix.rows))
+	for id := range ix.rows {
+		res[id], _ = ix.Score(id)
+	}
+	return res
+}
+
+// check returns an error when row cannot be added to the index
+func (ix *Index) check(row DataRow) error {
+	if len(row.Attrs) != ix.dimensions {
+		return fmt.Errorf("row %v has %v attributes, expected %v", row.ID, len(row.Attrs), ix.dimensions)
+	}
+
+	for _, a := range row.Attrs {
+		if math.IsNaN(a) || math.IsInf(a, 0) {
+			return fmt.Errorf("row %v has an invalid attribute value %v", row.ID, a)
+		}
+	}
+
+	return nil
+}
+
+// Insert adds rows to the index. The ids of the rows should
+// not be in the index already; nothing is added otherwise.
+func (ix *Index) Insert(rows ...DataRow) error {
+	seen := map[int]bool{}
+	for _, row := range rows {
+		if _, ok := ix.rows[row.ID]; ok || seen[row.ID] {
+			return fmt.Errorf("row %v is in the index already", row.ID)
+		}
+		seen[row.ID] = true
+
+		if err := ix.check(row); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range rows {
+		ix.insert(row)
+	}
+	return nil
+}
+
+// Delete removes the rows with the given ids from the index. Every id
+// should be in the index; nothing is removed otherwise.
+func (ix *Index) Delete(ids ...int) error {
+	seen := map[int]bool{}
+	for _, id := range ids {
+		if _, ok := ix.rows[id]; !ok || seen[id] {
+			return fmt.Errorf("row %v is not in the index", id)
+		}
+		seen[id] = true
+	}
+
+	for _, id := range ids {
+		ix.delete(id)
+	}
+	return nil
+}
+
+// Update replaces rows of the index by the rows with the same ids.
+// Every id should be in the index; nothing is replaced otherwise.
+func (ix *Index) Update(rows ...DataRow) error {
+	seen := map[int]bool{}
+	for _, row := range rows {
+		if _, ok := ix.rows[row.ID]; !ok || seen[row.ID] {
+			return fmt.Errorf("row %v is not in the index", row.ID)
+		}
+		seen[row.ID] = true
+
+		if err := ix.check(row); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range rows {
+		if a_equals_b(ix.rows[row.ID].Attrs, row.Attrs) {
+			ix.rows[row.ID] = row
+			continue
+		}
+
+		ix.delete(row.ID)
+		ix.insert(row)
+	}
+	return nil
+}
+
+func (ix *Index) insert(row DataRow) {
+	attrs := ix.o.attrs(row.Attrs)
+	key := newPointKey(attrs)
+
+	ix.addToDominators(attrs, 1)
+
+	if _, ok := ix.scores[key]; !ok {
+		ix.scores[key] = ix.grid.Dominated(attrs)
+	}
+
+	c := ix.cell(attrs, true)
+	if j := pointIndex(c.points, attrs); j >= 0 {
+		c.points[j].Count++
+	} else {
+		c.points = append(c.points, DataPoint{Attrs: attrs, Count: 1})
+	}
+	c.count++
+
+	ix.rows[row.ID] = row
+}
+
+func (ix *Index) delete(id int) {
+	attrs := ix.o.attrs(ix.rows[id].Attrs)
+	key := newPointKey(attrs)
+
+	ix.addToDominators(attrs, -1)
+
+	c := ix.cell(attrs, false)
+	j := pointIndex(c.points, attrs)
+	c.points[j].Count--
+	c.count--
+
+	if c.points[j].Count == 0 {
+		last := len(c.points) - 1
+		c.points[j] = c.points[last]
+		c.points = c.points[:last]
+		delete(ix.scores, key)
+	}
+	if c.count == 0 {
+		ix.deleteCell(c.coords)
+	}
+
+	delete(ix.rows, id)
+}
+
+// deleteCell removes the empty cell at coords from the grid,
+// moving the last cell of the grid in its place
+func (ix *Index) deleteCell(coords []float64) {
+	key := newPointKey(coords)
+	i := ix.cells[key]
+	last := len(ix.grid.cells) - 1
+
+	if i != last {
+		ix.grid.cells[i] = ix.grid.cells[last]
+		ix.cells[newPointKey(ix.grid.cells[i].coords)] = i
+	}
+	ix.grid.cells = ix.grid.cells[:last]
+	delete(ix.cells, key)
+}
+
+// pointIndex returns the position of the point attrs
+// in points, or -1 when it is not one of them
+func pointIndex(points []DataPoint, attrs []float64) int {
+	for j, p := range points {
+		if a_equals_b(p.Attrs, attrs) {
+			return j
+		}
+	}
+	return -1
+}
+
+// addToDominators adds delta to the score of every point
+// that dominates attrs. Only the cells greater or equal to
+// the cell of attrs in every coordinate can hold them.
+func (ix *Index) addToDominators(attrs []float64, delta int) {
+	coords := ix.grid.layout.cell(attrs)
+
+	for i := range ix.grid.cells {
+		c := &ix.grid.cells[i]
+		if !a_less_or_equal_b(coords, c.coords) {
+			continue
+		}
+
+		all := a_less_b(coords, c.coords)
+		for _, p := range c.points {
+			if all || a_dominates_b(p.Attrs, attrs) {
+				ix.scores[newPointKey(p.Attrs)] += delta
+			}
+		}
+	}
+}
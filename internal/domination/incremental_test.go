@@ -0,0 +1,43 @@
+package domination
+
+import "testing"
+
+func TestIndexErrors(t *testing.T) {
+	dsc := &DominationScoreCalculator{}
+	rows := rowsOf([]float64{1, 1}, []float64{2, 2}, []float64{3, 1})
+
+	if _, err := dsc.NewIndex(nil, []int{2, 2}); err == nil {
+		t.Error("expected an error for an empty dataset")
+	}
+
+	ix, err := dsc.NewIndex(rows, []int{2, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ix.Insert(DataRow{ID: 4, Attrs: []float64{5, 5}}, DataRow{ID: 1, Attrs: []float64{0, 0}}); err == nil {
+		t.Error("expected an error for an id in the index")
+	}
+	if err := ix.Insert(DataRow{ID: 4, Attrs: []float64{5}}); err == nil {
+		t.Error("expected an error for a missing attribute")
+	}
+	if err := ix.Delete(2, 7); err == nil {
+		t.Error("expected an error for an id not in the index")
+	}
+	if err := ix.Update(DataRow{ID: 7, Attrs: []float64{5, 5}}); err == nil {
+		t.Error("expected an error for an id not in the index")
+	}
+
+	// failed changes leave the index as it was
+	assertScores(t, ix.Scores(), map[int]int{1: 0, 2: 1, 3: 1})
+
+	if err := ix.Update(DataRow{ID: 1, Name: "renamed", Attrs: []float64{1, 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if score, ok := ix.Score(1); !ok || score != 0 {
+		t.Errorf("got score %v for row 1, want 0", score)
+	}
+	if _, ok := ix.Score(4); ok {
+		t.Error("found a score for a missing row")
+	}
+}
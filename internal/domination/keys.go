@@ -0,0 +1,62 @@
+package domination
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// keyDims is the number of values a pointKey holds in its array
+const keyDims = 6
+
+// pointKey identifies a point, or the coordinates of a grid cell, by
+// its values. It is comparable, so it can key a map without formatting
+// the values to a string. The first keyDims values are held as they
+// are; the ones past them, for points of more dimensions, are packed
+// into tail as their raw bits. Unused entries of head are 0, which
+// keeps the keys of points of different dimensions apart only through
+// tail, so the keys of a map should all have the same dimension.
+type pointKey struct {
+	head [keyDims]float64
+	tail string
+}
+
+// newPointKey returns the key of the values a. -0 and 0 are the same
+// value and get the same key.
+func newPointKey(a []float64) pointKey {
+	k := pointKey{}
+
+	var tail []byte
+	for i, v := range a {
+		if v == 0 {
+			v = 0
+		}
+
+		if i < keyDims {
+			k.head[i] = v
+			continue
+		}
+
+		if tail == nil {
+			tail = make([]byte, 8*(len(a)-keyDims))
+		}
+		binary.LittleEndian.PutUint64(tail[8*(i-keyDims):], math.Float64bits(v))
+	}
+	k.tail = string(tail)
+
+	return k
+}
+
+// values returns the d values the key was made from
+func (k pointKey) values(d int) []float64 {
+	a := make([]float64, d)
+	for i := range a {
+		if i < keyDims {
+			a[i] = k.head[i]
+			continue
+		}
+
+		j := 8 * (i - keyDims)
+		a[i] = math.Float64frombits(binary.LittleEndian.Uint64([]byte(k.tail[j : j+8])))
+	}
+	return a
+}
@@ -0,0 +1,32 @@
+package domination
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPointKey(t *testing.T) {
+	for _, a := range [][]float64{
+		{},
+		{1, 2.5, -3},
+		{1, 2, 3, 4, 5, 6},
+		{1, 2, 3, 4, 5, 6, 7, 0.125, -9, math.MaxFloat64},
+	} {
+		got := newPointKey(a).values(len(a))
+		if !a_equals_b(got, a) || len(got) != len(a) {
+			t.Errorf("values of the key of %v = %v", a, got)
+		}
+	}
+
+	if newPointKey([]float64{1, 2}) == newPointKey([]float64{2, 1}) {
+		t.Errorf("keys of (1, 2) and (2, 1) are equal")
+	}
+	if newPointKey([]float64{1, 2, 3, 4, 5, 6, 7}) == newPointKey([]float64{1, 2, 3, 4, 5, 6, 8}) {
+		t.Errorf("keys differing past the array are equal")
+	}
+
+	negZero := math.Copysign(0, -1)
+	if newPointKey([]float64{negZero, 1, 2, 3, 4, 5, negZero}) != newPointKey([]float64{0, 1, 2, 3, 4, 5, 0}) {
+		t.Errorf("keys of -0 and 0 differ")
+	}
+}
@@ -0,0 +1,170 @@
+package domination
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GridMode selects where the grid places the boundaries of its cells
+type GridMode int
+
+const (
+	// EqualWidth splits every attribute into gridSize steps
+	// of the same width between its minimum and maximum
+	EqualWidth GridMode = iota
+
+	// Quantile places the boundaries of every attribute at quantiles
+	// of DataStats.Histogram, so that each row of cells along it holds
+	// a similar number of rows. A value is never split across cells,
+	// so an attribute where many rows share a value gets fewer cells.
+	Quantile
+)
+
+var gridModeNames = []string{"equal", "quantile"}
+
+// String returns the name of the grid mode
+func (m GridMode) String() string {
+	if m >= 0 && int(m) < len(gridModeNames) {
+		return gridModeNames[m]
+	}
+	return fmt.Sprintf("GridMode(%d)", int(m))
+}
+
+// ParseGridMode converts the grid mode names used in the settings.json
+// files to a GridMode. An empty name is EqualWidth.
+func ParseGridMode(name string) (GridMode, error) {
+	if name == "" {
+		return EqualWidth, nil
+	}
+
+	for i, n := range gridModeNames {
+		if strings.EqualFold(name, n) {
+			return GridMode(i), nil
+		}
+	}
+	return EqualWidth, fmt.Errorf("unknown grid mode %q, should be one of %v", name, strings.Join(gridModeNames, ", "))
+}
+
+// gridLayout maps points to the grid cells they fall in
+type gridLayout struct {
+	stats    *DataStats
+	gridSize []int
+
+	// bounds holds the values the cells of every attribute start at,
+	// but for the first cell, in Quantile mode only
+	bounds [][]float64
+}
+
+// newGridLayout returns the layout of the grid over stats. Quantile
+// mode falls back to EqualWidth when stats have no histogram.
+func newGridLayout(stats *DataStats, gridSize []int, mode GridMode) *gridLayout {
+	gl := &gridLayout{
+		stats:    stats,
+		gridSize: gridSize,
+	}
+
+	if mode != Quantile || len(stats.Histogram) < len(stats.Max) {
+		return gl
+	}
+
+	bounds := make([][]float64, len(stats.Max))
+	for i := range bounds {
+		if stats.Histogram[i] == nil {
+			return gl
+		}
+		bounds[i] = quantileBounds(stats.Histogram[i], gridSize[i])
+	}
+	gl.bounds = bounds
+
+	return gl
+}
+
+// quantileBounds returns the values the cells start at, but for the
+// first one, when the values of hist are split into cells parts
+// holding about the same number of rows. Once a value shared by many
+// rows overfills a cell, the rows left are split evenly across the
+// cells left.
+func quantileBounds(hist map[float64]int, cells int) []float64 {
+	values := make([]float64, 0, len(hist))
+	remaining := 0
+	for v, c := range hist {
+		values = append(values, v)
+		remaining += c
+	}
+	sort.Float64s(values)
+
+	bounds := []float64{}
+	inCell := 0
+	for _, v := range values {
+		// a cell starts at v once the current one
+		// holds its share of the rows left
+		cellsLeft := cells - len(bounds)
+		if inCell > 0 && cellsLeft > 1 && inCell*cellsLeft >= remaining {
+			bounds = append(bounds, v)
+			remaining -= inCell
+			inCell = 0
+		}
+		inCell += hist[v]
+	}
+	return bounds
+}
+
+// cell returns the coordinates of the grid cell p falls in. Larger
+// values fall in cells with larger coordinates, so a cell lower than
+// another in every coordinate only holds points lower in every
+// attribute.
+func (gl *gridLayout) cell(p []float64) []float64 {
+	if gl.bounds == nil {
+		return translate(p, gl.stats, gl.gridSize...)
+	}
+
+	res := make([]float64, len(p))
+	for i := range p {
+		res[i] = float64(1 + gl.index(i, p[i]))
+	}
+	return res
+}
+
+// index returns the number of cells of attribute i before the one v
+// falls in
+func (gl *gridLayout) index(i int, v float64) int {
+	b := gl.bounds[i]
+	return sort.Search(len(b), func(j int) bool {
+		return b[j] > v
+	})
+}
+
+// fraction returns the share of its cell the approximate mode
+// estimates p dominates, the product of the positions of p within
+// the cell along every attribute
+func (gl *gridLayout) fraction(p []float64) float64 {
+	if gl.bounds == nil {
+		return translateApprx(p, gl.stats, gl.gridSize...)
+	}
+
+	if a_equals_b(gl.stats.Min, p) {
+		return 0
+	}
+
+	res := 1.0
+	for i := range p {
+		b := gl.bounds[i]
+		c := gl.index(i, p[i])
+
+		lo, hi := gl.stats.Min[i], gl.stats.Max[i]
+		if c > 0 {
+			lo = b[c-1]
+		}
+		if c < len(b) {
+			hi = b[c]
+		}
+		if hi <= lo {
+			continue
+		}
+
+		res = res * (p[i] - lo) / (hi - lo)
+	}
+
+	return res
+}
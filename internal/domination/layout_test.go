@@ -0,0 +1,80 @@
+package domination
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestQuantileBounds(t *testing.T) {
+	tests := []struct {
+		hist  map[float64]int
+		cells int
+		want  string
+	}{
+		{map[float64]int{1: 1, 2: 1, 3: 1, 4: 1}, 2, "[3]"},
+		{map[float64]int{1: 1, 2: 1, 3: 1, 4: 1}, 4, "[2 3 4]"},
+		{map[float64]int{1: 1, 2: 1, 3: 1, 4: 1}, 10, "[2 3 4]"},
+		{map[float64]int{1: 1, 2: 1, 3: 1, 4: 1}, 1, "[]"},
+		// most rows share the value 0, which stays in one cell
+		{map[float64]int{0: 90, 5: 4, 20: 3, 900: 3}, 4, "[5 20 900]"},
+		{map[float64]int{0: 90, 5: 4, 20: 3, 900: 3}, 2, "[5]"},
+		{map[float64]int{0: 50, 5: 20, 20: 20, 900: 10}, 4, "[5 20 900]"},
+		{map[float64]int{7: 10}, 5, "[]"},
+	}
+
+	for _, tt := range tests {
+		if got := fmt.Sprint(quantileBounds(tt.hist, tt.cells)); got != tt.want {
+			t.Errorf("quantileBounds(%v, %v) = %v, want %v", tt.hist, tt.cells, got, tt.want)
+		}
+	}
+}
+
+func TestQuantileLayout(t *testing.T) {
+	stats := &DataStats{
+		Min: []float64{0, -3},
+		Max: []float64{900, 1},
+		Histogram: []map[float64]int{
+			{0: 50, 5: 20, 20: 20, 900: 10},
+			{-3: 1, 1: 1},
+		},
+	}
+	gl := newGridLayout(stats, []int{4, 2}, Quantile)
+
+	for _, tt := range []struct {
+		p    []float64
+		cell string
+		frac float64
+	}{
+		{[]float64{0, -3}, "[1 1]", 0},
+		{[]float64{5, -3}, "[2 1]", 0},
+		{[]float64{12.5, -1}, "[2 1]", 0.5 * 0.5},
+		{[]float64{900, 1}, "[4 2]", 1},
+	} {
+		if got := fmt.Sprint(gl.cell(tt.p)); got != tt.cell {
+			t.Errorf("cell(%v) = %v, want %v", tt.p, got, tt.cell)
+		}
+		if got := gl.fraction(tt.p); got != tt.frac {
+			t.Errorf("fraction(%v) = %v, want %v", tt.p, got, tt.frac)
+		}
+	}
+
+	if newGridLayout(&DataStats{Min: []float64{0}, Max: []float64{1}}, []int{2}, Quantile).bounds != nil {
+		t.Errorf("got quantile bounds without a histogram")
+	}
+}
+
+func TestParseGridMode(t *testing.T) {
+	for _, m := range []GridMode{EqualWidth, Quantile} {
+		got, err := ParseGridMode(m.String())
+		if err != nil || got != m {
+			t.Errorf("ParseGridMode(%q) = %v, %v", m.String(), got, err)
+		}
+	}
+
+	if got, err := ParseGridMode(""); err != nil || got != EqualWidth {
+		t.Errorf("ParseGridMode(\"\") = %v, %v", got, err)
+	}
+	if _, err := ParseGridMode("log"); err == nil {
+		t.Error("expected an error for an unknown grid mode")
+	}
+}
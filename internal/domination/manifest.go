@@ -0,0 +1,232 @@
+package domination
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// Manifest describes a run of Calc or CalcStream: what was read, how it
+// was scored and how long every phase took, so that runs can be audited
+// and compared. It is written next to the output as JSON.
+type Manifest struct {
+	Tool    ToolVersion `json:"tool"`
+	Started time.Time   `json:"started"`
+
+	Input       string `json:"input"`
+	InputSHA256 string `json:"inputSha256"`
+	Output      string `json:"output"`
+
+	Dimensions   int      `json:"dimensions"`
+	GridSize     []int    `json:"gridSize"`
+	AutoGridSize bool     `json:"autoGridSize"`
+	GridMode     string   `json:"gridMode"`
+	Directions   []string `json:"directions"`
+	Mode         string   `json:"mode"`
+	Algorithm    string   `json:"algorithm"`
+	Streaming    bool     `json:"streaming"`
+	Workers      int      `json:"workers"`
+
+	Rows         int       `json:"rows"`
+	UniquePoints int       `json:"uniquePoints"`
+	Skipped      int       `json:"skipped"`
+	Imputed      int       `json:"imputed"`
+	Min          []float64 `json:"min"`
+	Max          []float64 `json:"max"`
+
+	Timings ManifestTimings `json:"timings"`
+}
+
+// ToolVersion identifies the binary that made a run, from the
+// build information Go embeds in it
+type ToolVersion struct {
+	Path      string `json:"path"`
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"goVersion"`
+}
+
+// ManifestTimings holds the Timings of a run in seconds
+type ManifestTimings struct {
+	Read  Seconds `json:"read"`
+	Grid  Seconds `json:"grid"`
+	Main  Seconds `json:"main"`
+	Write Seconds `json:"write"`
+	Total Seconds `json:"total"`
+
+	Cells       Seconds `json:"cells"`
+	Approximate Seconds `json:"approximate"`
+	Exact       Seconds `json:"exact"`
+}
+
+// Seconds is a duration written to JSON as a number of seconds
+type Seconds time.Duration
+
+func (s Seconds) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(s).Seconds())
+}
+
+func (s *Seconds) UnmarshalJSON(b []byte) error {
+	var v float64
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*s = Seconds(v * float64(time.Second))
+	return nil
+}
+
+// newManifest returns the manifest of a run that read inputFile,
+// wrote outputFile and found stats and points unique points
+func (dsc *DominationScoreCalculator) newManifest(started time.Time, inputFile string, outputFile string, approximate bool, gridSize []int, stats *DataStats, points int, timings Timings) (*Manifest, error) {
+	checksum, err := fileChecksum(inputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	directions := make([]string, len(stats.Max))
+	for i := range directions {
+		directions[i] = Maximize.String()
+		if i < len(dsc.Directions) {
+			directions[i] = dsc.Directions[i].String()
+		}
+	}
+
+	mode := "exact"
+	algorithm := dsc.exactAlgorithm(len(stats.Max)).String()
+	if approximate {
+		mode = "approximate"
+		algorithm = Grid.String()
+	}
+
+	workers := dsc.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	// without valid rows min and max are still infinite,
+	// which JSON cannot hold
+	min, max := stats.Min, stats.Max
+	if stats.Count == 0 {
+		min, max = nil, nil
+	}
+
+	return &Manifest{
+		Tool:    toolVersion(),
+		Started: started,
+
+		Input:       inputFile,
+		InputSHA256: checksum,
+		Output:      outputFile,
+
+		Dimensions: len(stats.Max),
+		GridSize:   gridSize,
+		GridMode:   dsc.GridMode.String(),
+		Directions: directions,
+		Mode:       mode,
+		Algorithm:  algorithm,
+		Workers:    workers,
+
+		Rows:         stats.Count,
+		UniquePoints: points,
+		Skipped:      stats.Skipped,
+		Imputed:      stats.Imputed,
+		Min:          min,
+		Max:          max,
+
+		Timings: ManifestTimings{
+			Read:  Seconds(timings.Read),
+			Grid:  Seconds(timings.Grid),
+			Main:  Seconds(timings.Main),
+			Write: Seconds(timings.Write),
+			Total: Seconds(timings.Total),
+
+			Cells:       Seconds(timings.Cells),
+			Approximate: Seconds(timings.Approximate),
+			Exact:       Seconds(timings.Exact),
+		},
+	}, nil
+}
+
+// writeManifest writes m next to outputFile
+func (dsc *DominationScoreCalculator) writeManifest(outputFile string, m *Manifest) error {
+	return createFile(manifestFile(outputFile), func(w io.Writer) error {
+		return WriteManifest(w, m)
+	})
+}
+
+// WriteManifest writes m to w as indented JSON
+func WriteManifest(w io.Writer, m *Manifest) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// ReadManifest reads a manifest written by WriteManifest
+func ReadManifest(filename string) (*Manifest, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+	err = json.Unmarshal(b, m)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// manifestFile returns the name of the manifest of outputFile,
+// "domination_manifest.json" for "domination.txt"
+func manifestFile(outputFile string) string {
+	return strings.TrimSuffix(outputFile, filepath.Ext(outputFile)) + "_manifest.json"
+}
+
+// fileChecksum returns the hex encoded SHA-256 of the content of filename
+func fileChecksum(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// toolVersion returns the version of the running binary
+func toolVersion() ToolVersion {
+	v := ToolVersion{
+		Version:   "unknown",
+		GoVersion: runtime.Version(),
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return v
+	}
+
+	v.Path = bi.Path
+	v.Version = bi.Main.Version
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			v.Revision = s.Value
+		case "vcs.modified":
+			v.Modified = s.Value == "true"
+		}
+	}
+	return v
+}
@@ -0,0 +1,154 @@
+package domination
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	content := "1\t1\t2\n2\t1\t2\n3\t4\t0\nx\t5\t5\n"
+	input := writeFile(t, content)
+	sum := sha256.Sum256([]byte(content))
+
+	cr := &CSVDatasetReader{
+		Columns: ColumnMapping{
+			Delimiter: "\t",
+			ID:        ColumnIndex(0),
+			Attrs:     []Column{ColumnIndex(1), ColumnIndex(2)},
+		},
+		Strictness: SkipRow,
+	}
+	dsc := &DominationScoreCalculator{Workers: 2, Directions: []Direction{Maximize, Minimize}}
+
+	for _, streaming := range []bool{false, true} {
+		output := filepath.Join(t.TempDir(), "domination.txt")
+
+		var err error
+		if streaming {
+			err = dsc.CalcStream(cr, input, output, true, []int{2, 2})
+		} else {
+			err = dsc.Calc(cr, input, output, true, []int{2, 2})
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m, err := ReadManifest(filepath.Join(filepath.Dir(output), "domination_manifest.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		name := fmt.Sprintf("streaming %v", streaming)
+		if m.InputSHA256 != hex.EncodeToString(sum[:]) {
+			t.Errorf("%v: got checksum %v", name, m.InputSHA256)
+		}
+		if m.Input != input || m.Output != output || m.Streaming != streaming {
+			t.Errorf("%v: got input %v, output %v, streaming %v", name, m.Input, m.Output, m.Streaming)
+		}
+		if m.Dimensions != 2 || fmt.Sprint(m.GridSize) != "[2 2]" || fmt.Sprint(m.Directions) != "[max min]" {
+			t.Errorf("%v: got dimensions %v, grid %v, directions %v", name, m.Dimensions, m.GridSize, m.Directions)
+		}
+		if m.Mode != "approximate" || m.Workers != 2 {
+			t.Errorf("%v: got mode %v and %v workers", name, m.Mode, m.Workers)
+		}
+		if m.Rows != 3 || m.UniquePoints != 2 || m.Skipped != 1 {
+			t.Errorf("%v: got %v rows, %v unique points, %v skipped, want 3, 2, 1", name, m.Rows, m.UniquePoints, m.Skipped)
+		}
+		if fmt.Sprint(m.Min, m.Max) != "[1 0] [4 2]" {
+			t.Errorf("%v: got min %v and max %v", name, m.Min, m.Max)
+		}
+		if m.Timings.Total <= 0 || m.Timings.Total < m.Timings.Read {
+			t.Errorf("%v: got timings %+v", name, m.Timings)
+		}
+		if m.Tool.GoVersion == "" || m.Started.IsZero() {
+			t.Errorf("%v: got tool %+v started at %v", name, m.Tool, m.Started)
+		}
+	}
+
+	if got := manifestFile(filepath.Join("out.d", "scores")); got != filepath.Join("out.d", "scores_manifest.json") {
+		t.Errorf("got manifest file %v", got)
+	}
+}
+
+// TestManifestRepeatedIDs checks that both paths count the rows read,
+// even when an id repeats
+func TestManifestRepeatedIDs(t *testing.T) {
+	input := writeFile(t, "1\t1\t2\n2\t3\t1\n1\t2\t2\n")
+	cr := &CSVDatasetReader{
+		Columns: ColumnMapping{
+			Delimiter: "\t",
+			ID:        ColumnIndex(0),
+			Attrs:     []Column{ColumnIndex(1), ColumnIndex(2)},
+		},
+	}
+	dsc := &DominationScoreCalculator{}
+
+	for _, streaming := range []bool{false, true} {
+		output := filepath.Join(t.TempDir(), "domination.txt")
+
+		var err error
+		if streaming {
+			err = dsc.CalcStream(cr, input, output, false, []int{2, 2})
+		} else {
+			err = dsc.Calc(cr, input, output, false, []int{2, 2})
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m, err := ReadManifest(manifestFile(output))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Rows != 3 || m.UniquePoints != 3 {
+			t.Errorf("streaming %v: got %v rows and %v unique points, want 3 and 3", streaming, m.Rows, m.UniquePoints)
+		}
+	}
+}
+
+// TestManifestNoRows checks that a run without valid rows, from a
+// header only file or a file whose every row is skipped, still writes
+// its manifest, without min and max
+func TestManifestNoRows(t *testing.T) {
+	for name, content := range map[string]string{
+		"header only": "id\ta\tb\n",
+		"all skipped": "id\ta\tb\n1\tx\t2\n2\t3\t\n",
+	} {
+		input := writeFile(t, content)
+		cr := &CSVDatasetReader{
+			Columns: ColumnMapping{
+				Delimiter: "\t",
+				Header:    true,
+				ID:        ColumnIndex(0),
+				Attrs:     []Column{ColumnIndex(1), ColumnIndex(2)},
+			},
+			Strictness: SkipRow,
+		}
+		dsc := &DominationScoreCalculator{}
+
+		for _, streaming := range []bool{false, true} {
+			output := filepath.Join(t.TempDir(), "domination.txt")
+
+			var err error
+			if streaming {
+				err = dsc.CalcStream(cr, input, output, false, []int{2, 2})
+			} else {
+				err = dsc.Calc(cr, input, output, false, []int{2, 2})
+			}
+			if err != nil {
+				t.Fatalf("%v, streaming %v: %v", name, streaming, err)
+			}
+
+			m, err := ReadManifest(manifestFile(output))
+			if err != nil {
+				t.Fatalf("%v, streaming %v: %v", name, streaming, err)
+			}
+			if m.Rows != 0 || m.UniquePoints != 0 || m.Min != nil || m.Max != nil {
+				t.Errorf("%v, streaming %v: got %v rows, %v unique points, min %v and max %v", name, streaming, m.Rows, m.UniquePoints, m.Min, m.Max)
+			}
+		}
+	}
+}
@@ -0,0 +1,411 @@
+package domination
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Strictness defines what a dataset reader does with a row
+// it cannot parse
+type Strictness int
+
+const (
+	// Fail stops reading and returns the error of the first
+	// malformed row
+	Fail Strictness = iota
+
+	// SkipRow drops every malformed row and counts it
+	// in DataStats.Skipped
+	SkipRow
+
+	// Impute replaces every malformed attribute value with the
+	// worst valid value of its column, the smallest one unless the
+	// attribute is minimized, and counts it in DataStats.Imputed.
+	// Rows with a malformed id are skipped, since there is nothing
+	// to impute an id from.
+	Impute
+)
+
+// ParseStrictness converts the strictness names used in the
+// settings.json files ("fail", "skip" and "impute") to a Strictness.
+// An empty name is the same as "fail".
+func ParseStrictness(name string) (Strictness, error) {
+	switch strings.ToLower(name) {
+	case "", "fail":
+		return Fail, nil
+	case "skip":
+		return SkipRow, nil
+	case "impute":
+		return Impute, nil
+	}
+	return Fail, fmt.Errorf("unknown strictness %q, should be one of fail, skip or impute", name)
+}
+
+var errMissingColumn = errors.New("missing column")
+
+// ParseError reports a value of a dataset file that could not be read
+type ParseError struct {
+	File   string
+	Line   int
+	Column int
+
+	// Field is the zero based index of the csv field,
+	// -1 when the whole record is malformed
+	Field int
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	if e.Field < 0 {
+		return fmt.Sprintf("%v:%v:%v: %v", e.File, e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("%v:%v:%v: field %v: %v", e.File, e.Line, e.Column, e.Field, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// RecordReader reads a csv dataset record by record and parses the
+// fields of the current record, keeping the position of every value
+// that fails to parse so that DatasetBuilder.AddRecord can apply
+// the Strictness of the reader
+type RecordReader struct {
+	File       string
+	Strictness Strictness
+
+	r      *csv.Reader
+	record []string
+	line   int
+	err    error
+
+	errs []*ParseError
+
+	// positions in the attrs slice of the current
+	// record that failed to parse
+	invalidAttrs []int
+	attrs        int
+	otherErrs    int
+}
+
+// NewRecordReader returns a RecordReader for the csv data read from r.
+// filename is only used to give context to the reported errors.
+func NewRecordReader(r io.Reader, filename string, comma rune, strictness Strictness) *RecordReader {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	return &RecordReader{
+		File:       filename,
+		Strictness: strictness,
+		r:          cr,
+	}
+}
+
+// Next advances to the next record. It returns false at the end of
+// the file or on an I/O error, which is then returned by Err.
+// Malformed csv records do not stop the reader; they are reported
+// through AddRecord like any other invalid value.
+func (rr *RecordReader) Next() bool {
+	rr.errs = rr.errs[:0]
+	rr.invalidAttrs = rr.invalidAttrs[:0]
+	rr.attrs = 0
+	rr.otherErrs = 0
+
+	record, err := rr.r.Read()
+	if err == io.EOF {
+		rr.record = nil
+		return false
+	}
+
+	var pe *csv.ParseError
+	if errors.As(err, &pe) {
+		rr.record = nil
+		rr.line = pe.Line
+		rr.errs = append(rr.errs, &ParseError{
+			File:   rr.File,
+			Line:   pe.Line,
+			Column: pe.Column,
+			Field:  -1,
+			Err:    pe.Err,
+		})
+		rr.otherErrs++
+		return true
+	}
+
+	if err != nil {
+		rr.record = nil
+		rr.err = fmt.Errorf("%v: %w", rr.File, err)
+		return false
+	}
+
+	rr.record = record
+	rr.line, _ = rr.r.FieldPos(0)
+	return true
+}
+
+// Err returns the I/O error that stopped Next, if any
+func (rr *RecordReader) Err() error {
+	return rr.err
+}
+
+// fail records a parse error for field i of the current record
+func (rr *RecordReader) fail(i int, err error) {
+	pe := &ParseError{
+		File:  rr.File,
+		Line:  rr.line,
+		Field: i,
+		Err:   err,
+	}
+	if i < len(rr.record) {
+		pe.Line, pe.Column = rr.r.FieldPos(i)
+	}
+	rr.errs = append(rr.errs, pe)
+}
+
+func (rr *RecordReader) field(i int) (string, bool) {
+	if rr.record == nil {
+		return "", false
+	}
+	if i >= len(rr.record) {
+		rr.fail(i, errMissingColumn)
+		return "", false
+	}
+	return rr.record[i], true
+}
+
+// Field returns field i of the current record
+func (rr *RecordReader) Field(i int) string {
+	v, ok := rr.field(i)
+	if !ok && rr.record != nil {
+		rr.otherErrs++
+	}
+	return v
+}
+
+// Int parses field i of the current record as an integer
+func (rr *RecordReader) Int(i int) int {
+	s, ok := rr.field(i)
+	if !ok {
+		if rr.record != nil {
+			rr.otherErrs++
+		}
+		return 0
+	}
+
+	v, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		rr.fail(i, err)
+		rr.otherErrs++
+		return 0
+	}
+	return v
+}
+
+// Attrs parses the given fields of the current record
+// as the attribute values of a row
+func (rr *RecordReader) Attrs(fields ...int) []float64 {
+	res := make([]float64, len(fields))
+
+	for j, i := range fields {
+		pos := rr.attrs
+		rr.attrs++
+
+		s, ok := rr.field(i)
+		if !ok {
+			rr.invalidAttrs = append(rr.invalidAttrs, pos)
+			continue
+		}
+
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err == nil && (math.IsNaN(f) || math.IsInf(f, 0)) {
+			err = fmt.Errorf("invalid attribute value %q", s)
+		}
+		if err != nil {
+			rr.fail(i, err)
+			rr.invalidAttrs = append(rr.invalidAttrs, pos)
+			continue
+		}
+
+		res[j] = f
+	}
+
+	return res
+}
+
+// DatasetBuilder collects the rows of a dataset into the row map, the
+// DataStats and the unique DataPoints returned by DatasetReader
+type DatasetBuilder struct {
+	rows   map[int]DataRow
+	stats  *DataStats
+	unique map[pointKey]int
+
+	// keepRows is false when only the stats and the unique
+	// points are collected, count is the number of rows added
+	keepRows bool
+	count    int
+
+	// rows waiting for the worst values of the
+	// columns to impute their invalid attributes
+	imputed      []DataRow
+	imputedAttrs [][]int
+
+	// Directions holds the preference direction of every attribute,
+	// which decides the worst value Impute fills in. When empty,
+	// larger values are better on every attribute.
+	Directions []Direction
+}
+
+// NewDatasetBuilder returns an empty DatasetBuilder for rows
+// with the given number of attributes
+func NewDatasetBuilder(dimensions int) *DatasetBuilder {
+	stats := &DataStats{
+		Max:       make([]float64, dimensions),
+		Min:       make([]float64, dimensions),
+		Histogram: make([]map[float64]int, dimensions),
+		Count:     0,
+	}
+
+	for i := range stats.Max {
+		stats.Max[i] = math.Inf(-1)
+		stats.Min[i] = math.Inf(1)
+	}
+
+	return &DatasetBuilder{
+		rows:     map[int]DataRow{},
+		stats:    stats,
+		unique:   map[pointKey]int{},
+		keepRows: true,
+	}
+}
+
+// newPointsBuilder returns a DatasetBuilder that does not keep the
+// rows, so that its memory grows with the unique points only
+func newPointsBuilder(dimensions int) *DatasetBuilder {
+	b := NewDatasetBuilder(dimensions)
+	b.keepRows = false
+	return b
+}
+
+// Add adds a valid row to the dataset
+func (b *DatasetBuilder) Add(row DataRow) {
+	attrs := row.Attrs
+	stats := b.stats
+
+	// stats max / min
+	{
+		for j := range attrs {
+			if attrs[j] > stats.Max[j] {
+				stats.Max[j] = attrs[j]
+			}
+		}
+
+		for j := range attrs {
+			if attrs[j] < stats.Min[j] {
+				stats.Min[j] = attrs[j]
+			}
+		}
+	}
+
+	// histogram
+	{
+		for i, a := range attrs {
+			if stats.Histogram[i] == nil {
+				stats.Histogram[i] = map[float64]int{}
+			}
+			stats.Histogram[i][a]++
+		}
+	}
+
+	// unique
+	{
+		b.unique[newPointKey(attrs)]++
+	}
+
+	b.count++
+	if b.keepRows {
+		b.rows[row.ID] = row
+	}
+}
+
+// AddRecord adds the row parsed from the current record of rr, applying
+// the strictness of rr when some of its fields could not be parsed.
+// The returned error is not nil only when the reader must stop.
+func (b *DatasetBuilder) AddRecord(rr *RecordReader, row DataRow) error {
+	if len(rr.errs) == 0 {
+		b.Add(row)
+		return nil
+	}
+
+	switch rr.Strictness {
+	case SkipRow:
+		b.stats.Skipped++
+		return nil
+
+	case Impute:
+		if rr.otherErrs > 0 {
+			b.stats.Skipped++
+			return nil
+		}
+
+		b.imputed = append(b.imputed, row)
+		b.imputedAttrs = append(b.imputedAttrs, append([]int{}, rr.invalidAttrs...))
+		return nil
+	}
+
+	return rr.errs[0]
+}
+
+// worstValues returns the worst value of every attribute of stats, the
+// minimum unless the attribute is minimized in directions, or 0 for the
+// attributes without a valid value
+func worstValues(stats *DataStats, directions []Direction) []float64 {
+	res := make([]float64, len(stats.Min))
+	for i := range res {
+		res[i] = stats.Min[i]
+		if i < len(directions) && directions[i] == Minimize {
+			res[i] = stats.Max[i]
+		}
+		if math.IsInf(res[i], 0) {
+			res[i] = 0
+		}
+	}
+	return res
+}
+
+// Build imputes the pending rows and returns the dataset
+func (b *DatasetBuilder) Build() (map[int]DataRow, *DataStats, []DataPoint) {
+	stats := b.stats
+
+	// the worst values are taken over the valid values only,
+	// so they are fixed before the imputed rows are added
+	worst := worstValues(stats, b.Directions)
+
+	for i, row := range b.imputed {
+		for _, j := range b.imputedAttrs[i] {
+			row.Attrs[j] = worst[j]
+			stats.Imputed++
+		}
+		b.Add(row)
+	}
+	b.imputed = nil
+	b.imputedAttrs = nil
+
+	dataPoints := []DataPoint{}
+
+	for k, v := range b.unique {
+		dataPoints = append(dataPoints, DataPoint{
+			Count: v,
+			Attrs: k.values(len(stats.Max)),
+		})
+	}
+
+	stats.Count = b.count
+	return b.rows, stats, dataPoints
+}
@@ -0,0 +1,21 @@
+package domination
+
+// ReferenceScores returns the domination score of every row by
+// comparing each row with every other one. It takes O(n²) time and
+// is only meant to validate the grid based calculation on small
+// datasets. Attributes without a direction are maximized.
+func ReferenceScores(rows []DataRow, directions ...Direction) map[int]int {
+	res := make(map[int]int, len(rows))
+
+	for _, a := range rows {
+		score := 0
+		for _, b := range rows {
+			if dominates(a.Attrs, b.Attrs, directions) {
+				score++
+			}
+		}
+		res[a.ID] = score
+	}
+
+	return res
+}
@@ -0,0 +1,157 @@
+package domination
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// Result holds the domination scores of a dataset
+type Result struct {
+	// Scores maps the id of every row to its domination score
+	Scores map[int]int
+	Stats  *DataStats
+
+	// Points is the number of unique points among the rows
+	Points int
+
+	// GridSize is the grid size the scores were counted with, the
+	// one picked when an automatic grid size was asked for. It is
+	// left as given by the exact algorithms that use no grid.
+	GridSize []int
+
+	// Bounds maps the id of every row to the bounds
+	// of its score, in approximate mode only
+	Bounds map[int]Bounds
+
+	Timings Timings
+}
+
+// Timings holds the duration of every phase of a calculation.
+// Cells, Approximate and Exact are summed over all workers,
+// so they can add up to more than Main.
+type Timings struct {
+	Read  time.Duration
+	Grid  time.Duration
+	Main  time.Duration
+	Write time.Duration
+	Total time.Duration
+
+	Cells       time.Duration
+	Approximate time.Duration
+	Exact       time.Duration
+}
+
+// RowIterator yields the rows of a dataset one at a time
+type RowIterator interface {
+	// Next returns the next row and false
+	// when there are no more rows
+	Next() (DataRow, bool)
+}
+
+type sliceIterator struct {
+	rows []DataRow
+	i    int
+}
+
+func (si *sliceIterator) Next() (DataRow, bool) {
+	if si.i >= len(si.rows) {
+		return DataRow{}, false
+	}
+	si.i++
+	return si.rows[si.i-1], true
+}
+
+// Score returns the domination score of every row
+func (dsc *DominationScoreCalculator) Score(rows []DataRow, approximate bool, gridSize []int) (*Result, error) {
+	return dsc.ScoreIter(&sliceIterator{rows: rows}, approximate, gridSize)
+}
+
+// ScoreIter returns the domination score of every row yielded by it.
+// All rows should have the same number of attributes as the first one.
+func (dsc *DominationScoreCalculator) ScoreIter(it RowIterator, approximate bool, gridSize []int) (*Result, error) {
+	total := time.Now()
+
+	rows, stats, unique, err := readRows(it, gridSize)
+	if err != nil {
+		return nil, err
+	}
+	read := time.Since(total)
+
+	res, err := dsc.score(rows, stats, unique, approximate, gridSize)
+	if err != nil {
+		return nil, err
+	}
+	res.Timings.Read = read
+	res.Timings.Total = time.Since(total)
+
+	return res, nil
+}
+
+// readRows collects the rows yielded by it the way a DatasetReader
+// does, checking that they all have as many attributes as the first
+// row and that gridSize, unless empty, has a size of at least 1 for
+// every attribute
+func readRows(it RowIterator, gridSize []int) (map[int]DataRow, *DataStats, []DataPoint, error) {
+	var b *DatasetBuilder
+	dimensions := 0
+
+	for {
+		row, ok := it.Next()
+		if !ok {
+			break
+		}
+
+		if b == nil {
+			dimensions = len(row.Attrs)
+			if len(gridSize) > 0 {
+				err := checkGridSize(gridSize, dimensions)
+				if err != nil {
+					return nil, nil, nil, err
+				}
+			}
+			b = NewDatasetBuilder(dimensions)
+		}
+
+		if len(row.Attrs) != dimensions {
+			return nil, nil, nil, fmt.Errorf("row %v has %v attributes, expected %v", row.ID, len(row.Attrs), dimensions)
+		}
+
+		for _, a := range row.Attrs {
+			if math.IsNaN(a) || math.IsInf(a, 0) {
+				return nil, nil, nil, fmt.Errorf("row %v has an invalid attribute value %v", row.ID, a)
+			}
+		}
+
+		b.Add(row)
+	}
+
+	if b == nil {
+		b = NewDatasetBuilder(0)
+	}
+
+	rows, stats, unique := b.Build()
+	return rows, stats, unique, nil
+}
+
+// WriteScores writes the scores to w as tab separated id and
+// domination score lines, in id order, after an "id\tdom" header
+func WriteScores(w io.Writer, scores map[int]int) error {
+	ids := make([]int, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString("id\tdom\n")
+	for _, id := range ids {
+		fmt.Fprintf(bw, "%v\t%v\n", id, scores[id])
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,192 @@
+package domination
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// Skyline holds the rows of a dataset that are not dominated
+// by any other row, i.e. its Pareto frontier
+type Skyline struct {
+	// Points are the unique attribute vectors on the skyline, with
+	// the number of rows sharing each vector in Count
+	Points []DataPoint
+
+	// Rows are the rows on the skyline, in id order
+	Rows []DataRow
+
+	Stats *DataStats
+}
+
+// SkylineRows returns the skyline of rows
+func (dsc *DominationScoreCalculator) SkylineRows(rows []DataRow, gridSize []int) (*Skyline, error) {
+	r, stats, unique, err := readRows(&sliceIterator{rows: rows}, gridSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return dsc.skyline(r, stats, unique, gridSize)
+}
+
+// SkylineFile reads the dataset in inputFile with dataReader
+// and returns its skyline
+func (dsc *DominationScoreCalculator) SkylineFile(dataReader DatasetReader, inputFile string, gridSize []int) (*Skyline, error) {
+	t1 := time.Now()
+	rows, stats, unique, err := dataReader.ReadDataset(inputFile)
+	if err != nil {
+		return nil, err
+	}
+	dsc.logf("reading done in: %v\n", time.Since(t1))
+
+	return dsc.skyline(rows, stats, unique, gridSize)
+}
+
+// CalcSkyline reads the dataset in inputFile with dataReader and
+// writes the rows on its skyline to outputFile
+func (dsc *DominationScoreCalculator) CalcSkyline(dataReader DatasetReader, inputFile string, outputFile string, gridSize []int) error {
+	sky, err := dsc.SkylineFile(dataReader, inputFile, gridSize)
+	if err != nil {
+		return err
+	}
+
+	fd, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+
+	err = WriteSkyline(fd, sky)
+	if err != nil {
+		fd.Close()
+		return err
+	}
+
+	return fd.Close()
+}
+
+// skyline finds the unique points that no other point dominates.
+//
+// A cell can be skipped as a whole when a non empty cell is greater
+// in every coordinate, since every point of that cell dominates every
+// point of the skipped one. The points of the remaining cells only
+// have to be compared with the points of the cells that are greater
+// or equal in every coordinate.
+func (dsc *DominationScoreCalculator) skyline(rows map[int]DataRow, stats *DataStats, unique []DataPoint, gridSize []int) (*Skyline, error) {
+	t1 := time.Now()
+
+	o, err := dsc.orient(stats)
+	if err != nil {
+		return nil, err
+	}
+
+	oriented := o.dataStats()
+	points := o.points(unique)
+	gridSize, err = dsc.gridSizeFor(oriented, points, false, gridSize)
+	if err != nil {
+		return nil, err
+	}
+
+	grid := newGrid(points, newGridLayout(oriented, gridSize, dsc.GridMode))
+
+	res := &Skyline{
+		Points: []DataPoint{},
+		Rows:   []DataRow{},
+		Stats:  stats,
+	}
+
+	pruned := 0
+
+	for i := range grid {
+		cell := grid[i].coords
+		sum := grid[i].sum
+
+		dominated := false
+		upper := []DataPoint{}
+
+		// the cells that can hold dominating points have a greater
+		// or equal coordinate sum, so they are sorted before cell i
+		for _, j := range grid[:i+1] {
+			if j.sum < sum {
+				continue
+			}
+
+			if a_less_b(cell, j.coords) {
+				dominated = true
+				break
+			}
+
+			if a_less_or_equal_b(cell, j.coords) {
+				upper = append(upper, j.points...)
+			}
+		}
+
+		if dominated {
+			pruned++
+			continue
+		}
+
+		for _, n := range grid[i].points {
+			onSkyline := true
+			for _, u := range upper {
+				if a_dominates_b(u.Attrs, n.Attrs) {
+					onSkyline = false
+					break
+				}
+			}
+
+			if onSkyline {
+				res.Points = append(res.Points, n)
+			}
+		}
+	}
+
+	sort.Slice(res.Points, datapointSortFn(res.Points))
+
+	skyline := make(map[pointKey]bool, len(res.Points))
+	for _, p := range res.Points {
+		skyline[newPointKey(p.Attrs)] = true
+	}
+
+	for _, row := range rows {
+		if skyline[newPointKey(o.attrs(row.Attrs))] {
+			res.Rows = append(res.Rows, row)
+		}
+	}
+
+	// back to the original attribute values
+	res.Points = o.points(res.Points)
+	sort.Slice(res.Rows, func(i, j int) bool {
+		return res.Rows[i].ID < res.Rows[j].ID
+	})
+
+	dsc.logf("cells: %v\tpruned: %v\tskyline points: %v\trows: %v\n", len(grid), pruned, len(res.Points), len(res.Rows))
+	dsc.logf("skyline done in: %v\n", time.Since(t1))
+
+	return res, nil
+}
+
+// WriteSkyline writes the rows of the skyline to w as tab separated
+// lines with the id, the number of rows sharing the same attributes
+// and the attributes, after an "id\tmultiplicity\tattrs" header
+func WriteSkyline(w io.Writer, sky *Skyline) error {
+	multiplicity := make(map[pointKey]int, len(sky.Points))
+	for _, p := range sky.Points {
+		multiplicity[newPointKey(p.Attrs)] = p.Count
+	}
+
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString("id\tmultiplicity\tattrs\n")
+	for _, row := range sky.Rows {
+		fmt.Fprintf(bw, "%v\t%v", row.ID, multiplicity[newPointKey(row.Attrs)])
+		for _, a := range row.Attrs {
+			fmt.Fprintf(bw, "\t%v", a)
+		}
+		bw.WriteString("\n")
+	}
+
+	return bw.Flush()
+}
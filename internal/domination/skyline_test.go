@@ -0,0 +1,56 @@
+package domination
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// referenceSkyline returns the sorted ids of the rows
+// that are not dominated by any other row
+func referenceSkyline(rows []DataRow, directions ...Direction) []int {
+	res := []int{}
+	for _, a := range rows {
+		dominated := false
+		for _, b := range rows {
+			if dominates(b.Attrs, a.Attrs, directions) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			res = append(res, a.ID)
+		}
+	}
+	sort.Ints(res)
+	return res
+}
+
+func TestSkyline(t *testing.T) {
+	rows := rowsOf(
+		[]float64{1, 1}, []float64{3, 1}, []float64{1, 3},
+		[]float64{2, 2}, []float64{2, 2}, []float64{0, 3},
+	)
+
+	dsc := &DominationScoreCalculator{}
+	sky, err := dsc.SkylineRows(rows, []int{2, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []int{}
+	for _, row := range sky.Rows {
+		ids = append(ids, row.ID)
+	}
+	if fmt.Sprint(ids) != "[2 3 4 5]" {
+		t.Errorf("got skyline rows %v, want [2 3 4 5]", ids)
+	}
+
+	counts := map[pointKey]int{}
+	for _, p := range sky.Points {
+		counts[newPointKey(p.Attrs)] = p.Count
+	}
+	if len(counts) != 3 || counts[newPointKey([]float64{2, 2})] != 2 {
+		t.Errorf("got skyline points %v, want 3 points with (2, 2) twice", counts)
+	}
+}
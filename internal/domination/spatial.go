@@ -0,0 +1,229 @@
+package domination
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// SpatialIndex holds the unique points of a dataset and answers
+// dominance count queries over them. The points are in the orientation
+// where larger is better on every attribute.
+type SpatialIndex interface {
+	// Dominated returns the number of rows
+	// of the index the point attrs dominates
+	Dominated(attrs []float64) int
+}
+
+// NewGridIndex returns the grid of gridSize cells per attribute over
+// points as a SpatialIndex, with the cell boundaries of mode. Every
+// query scans all of the cells, adding the cells lower in every
+// coordinate whole and comparing the points of the lower or equal ones
+// one by one. An empty gridSize picks one from the points.
+func NewGridIndex(points []DataPoint, stats *DataStats, gridSize []int, mode GridMode) SpatialIndex {
+	if len(gridSize) == 0 {
+		gridSize = autoGridSize(stats, points, mode, 1)
+	}
+	return newGridIndex(append([]DataPoint{}, points...), newGridLayout(stats, gridSize, mode))
+}
+
+// gridIndex is the grid the grid based scores, top-k and Index count
+// with. Its cells are sorted by newGrid, but for the ones of an Index,
+// which are kept in the order they were made.
+type gridIndex struct {
+	cells  []gridCell
+	layout *gridLayout
+}
+
+// newGridIndex splits points into the cells of layout, sorting points
+func newGridIndex(points []DataPoint, layout *gridLayout) *gridIndex {
+	return &gridIndex{
+		cells:  newGrid(points, layout),
+		layout: layout,
+	}
+}
+
+func (gi *gridIndex) Dominated(attrs []float64) int {
+	n := DataPoint{Attrs: attrs}
+
+	partial := 0
+	whole := gi.below(0, gi.layout.cell(attrs), func(j int) {
+		partial += partialScore(n, gi.cells[j].points)
+	})
+	return whole + partial
+}
+
+// below compares the cells from cells[from] on with the cell at
+// coords. It returns the number of rows in the cells lower in every
+// coordinate, which every point of the cell at coords dominates, and
+// calls partial with the index of every cell lower or equal, whose
+// points have to be compared one by one. Since the cells below a cell have a lower or
+// equal coordinate sum, sorted cells can be scanned from the cell at
+// coords on.
+func (gi *gridIndex) below(from int, coords []float64, partial func(j int)) int {
+	cells := gi.cells
+	sum := sumSlice(coords)
+
+	whole := 0
+	for j := from; j < len(cells); j++ {
+		c := &cells[j]
+		if c.sum > sum {
+			continue
+		}
+
+		if a_less_b(c.coords, coords) {
+			whole += c.count
+		} else if a_less_or_equal_b(c.coords, coords) {
+			partial(j)
+		}
+	}
+	return whole
+}
+
+// kdLeafSize is the largest number of points
+// a k-d tree node is not split any further at
+const kdLeafSize = 8
+
+// NewKDTree returns a k-d tree over points as a SpatialIndex. Every
+// node keeps the bounding box of its points and the number of rows
+// they stand for, so a query adds the nodes lying below the point
+// whole and skips the ones with no point lower or equal to it,
+// descending only into the nodes its lower orthant cuts through.
+func NewKDTree(points []DataPoint) SpatialIndex {
+	t := &kdTree{
+		points: append([]DataPoint{}, points...),
+	}
+	if len(t.points) > 0 {
+		t.build(0, len(t.points))
+	}
+	return t
+}
+
+type kdTree struct {
+	points []DataPoint
+	nodes  []kdNode
+}
+
+type kdNode struct {
+	// min and max bound the points of the node,
+	// points[lo:hi] of the tree
+	min, max []float64
+	lo, hi   int
+	count    int
+
+	// left and right are the children of
+	// the node, or -1 for the leaves
+	left, right int
+}
+
+// build adds the node of points[lo:hi] and its children, splitting
+// at the median of the attribute the points spread the most on
+func (t *kdTree) build(lo, hi int) int {
+	points := t.points[lo:hi]
+
+	n := kdNode{
+		min:   append([]float64{}, points[0].Attrs...),
+		max:   append([]float64{}, points[0].Attrs...),
+		lo:    lo,
+		hi:    hi,
+		left:  -1,
+		right: -1,
+	}
+	for _, p := range points {
+		n.count += p.Count
+		for k, v := range p.Attrs {
+			if v < n.min[k] {
+				n.min[k] = v
+			}
+			if v > n.max[k] {
+				n.max[k] = v
+			}
+		}
+	}
+
+	i := len(t.nodes)
+	t.nodes = append(t.nodes, n)
+
+	if len(points) <= kdLeafSize {
+		return i
+	}
+
+	split := 0
+	for k := range n.min {
+		if n.max[k]-n.min[k] > n.max[split]-n.min[split] {
+			split = k
+		}
+	}
+
+	sort.Slice(points, func(a, b int) bool {
+		return points[a].Attrs[split] < points[b].Attrs[split]
+	})
+	mid := lo + len(points)/2
+
+	left := t.build(lo, mid)
+	right := t.build(mid, hi)
+	t.nodes[i].left, t.nodes[i].right = left, right
+
+	return i
+}
+
+func (t *kdTree) Dominated(attrs []float64) int {
+	if len(t.nodes) == 0 {
+		return 0
+	}
+
+	score := 0
+	stack := []int{0}
+	for len(stack) > 0 {
+		n := &t.nodes[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+
+		if !a_less_or_equal_b(n.min, attrs) {
+			// no point of the node is lower or equal to attrs
+			continue
+		}
+
+		if a_less_or_equal_b(n.max, attrs) && !a_equals_b(n.max, attrs) {
+			// every point of the node is lower or equal to
+			// attrs and none of them is equal to it
+			score += n.count
+			continue
+		}
+
+		if n.left < 0 {
+			for _, p := range t.points[n.lo:n.hi] {
+				if a_dominates_b(attrs, p.Attrs) {
+					score += p.Count
+				}
+			}
+			continue
+		}
+
+		stack = append(stack, n.left, n.right)
+	}
+	return score
+}
+
+// queryAll returns the number of rows every point dominates, in the
+// order of points, querying index from workers goroutines
+func queryAll(index SpatialIndex, points []DataPoint, workers int) []int {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	scores := make([]int, len(points))
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(points); i += workers {
+				scores[i] = index.Dominated(points[i].Attrs)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	return scores
+}
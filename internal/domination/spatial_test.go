@@ -0,0 +1,72 @@
+package domination
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestSpatialIndexes(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+
+	for d := 1; d <= 4; d++ {
+		for _, max := range []int{3, 50} {
+			rows := randomRows(r, 1+r.Intn(400), d, max, false)
+			_, stats, unique, err := readRows(&sliceIterator{rows: rows}, gridOf(d, 4))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			indexes := map[string]SpatialIndex{
+				"grid":          NewGridIndex(unique, stats, gridOf(d, 4), EqualWidth),
+				"quantile grid": NewGridIndex(unique, stats, gridOf(d, 4), Quantile),
+				"k-d tree":      NewKDTree(unique),
+			}
+
+			// the indexed points and others, some
+			// of them outside of the range of the data
+			queries := randomRows(r, 100, d, max+2, false)
+			for _, p := range unique {
+				queries = append(queries, DataRow{Attrs: p.Attrs})
+			}
+
+			for _, q := range queries {
+				want := 0
+				for _, row := range rows {
+					if a_dominates_b(q.Attrs, row.Attrs) {
+						want++
+					}
+				}
+
+				for name, index := range indexes {
+					if got := index.Dominated(q.Attrs); got != want {
+						t.Errorf("%vD max %v %v: %v dominates %v rows, want %v", d, max, name, q.Attrs, got, want)
+					}
+				}
+			}
+		}
+	}
+
+	if got := NewKDTree(nil).Dominated([]float64{1, 2}); got != 0 {
+		t.Errorf("empty k-d tree: got %v", got)
+	}
+}
+
+func TestParseAlgorithm(t *testing.T) {
+	for _, a := range []Algorithm{Auto, Grid, DivideAndConquer, KDTree} {
+		got, err := ParseAlgorithm(a.String())
+		if err != nil || got != a {
+			t.Errorf("ParseAlgorithm(%q) = %v, %v", a.String(), got, err)
+		}
+	}
+
+	if got, err := ParseAlgorithm(""); err != nil || got != Auto {
+		t.Errorf("ParseAlgorithm(\"\") = %v, %v", got, err)
+	}
+	if _, err := ParseAlgorithm("quadtree"); err == nil {
+		t.Error("expected an error for an unknown algorithm")
+	}
+	if got := fmt.Sprint(Algorithm(9)); got != "Algorithm(9)" {
+		t.Errorf("got %v", got)
+	}
+}
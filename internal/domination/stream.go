@@ -0,0 +1,138 @@
+package domination
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DatasetStreamer reads a dataset in two passes instead of holding all
+// of its rows in memory
+type DatasetStreamer interface {
+	// ReadPoints returns the stats and the unique points of the dataset
+	ReadPoints(filename string) (*DataStats, []DataPoint, error)
+
+	// StreamRows calls fn with every row of the dataset, with
+	// the attributes imputed the same way as in ReadPoints
+	StreamRows(filename string, stats *DataStats, fn func(row DataRow) error) error
+}
+
+// CalcStream works like Calc for datasets too large to hold in memory.
+// The first pass over inputFile collects the stats and the unique points,
+// which are scored, and the second writes the score of every row as it
+// is read, so memory grows with the unique points rather than the rows.
+// The scores are written in file order instead of id order, one line
+// for every row, so an id repeated in inputFile is written once for
+// every row it appears in, and counted that many times in the accuracy,
+// where Calc keeps the score of its last row only.
+func (dsc *DominationScoreCalculator) CalcStream(streamer DatasetStreamer, inputFile string, outputFile string, approximate bool, gridSize []int) error {
+	total := time.Now()
+
+	dsc.logf("reading points of %v...\n", inputFile)
+
+	t1 := time.Now()
+	stats, unique, err := streamer.ReadPoints(inputFile)
+	if err != nil {
+		return err
+	}
+	read := time.Since(t1)
+
+	dsc.logf("reading done in: %v\tunique points: %v of %v\n", read, len(unique), stats.Count)
+	if stats.Skipped > 0 || stats.Imputed > 0 {
+		dsc.logf("skipped rows: %v, imputed values: %v\n", stats.Skipped, stats.Imputed)
+	}
+
+	ps, timings, err := dsc.scorePoints(stats, unique, approximate, gridSize)
+	if err != nil {
+		return err
+	}
+	timings.Read = read
+	points := len(unique)
+	unique = nil
+
+	t1 = time.Now()
+
+	// write outfile
+	fd, err := os.Create(outputFile)
+	if err != nil {
+		fd, err = os.Create("dom_out_new.txt")
+		if err != nil {
+			return err
+		}
+	}
+	defer fd.Close()
+
+	bw := bufio.NewWriter(fd)
+	bw.WriteString("id\tdom\n")
+
+	var bfd *os.File
+	var bounds *bufio.Writer
+	sums := accuracySums{}
+	if approximate {
+		bfd, err = os.Create(siblingFile(fd.Name(), "_bounds"))
+		if err != nil {
+			return err
+		}
+		defer bfd.Close()
+
+		bounds = bufio.NewWriter(bfd)
+		bounds.WriteString("id\tdom\tlower\tupper\n")
+	}
+
+	err = streamer.StreamRows(inputFile, stats, func(row DataRow) error {
+		score, b := ps.get(row.Attrs)
+
+		_, err := fmt.Fprintf(bw, "%v\t%v\n", row.ID, score)
+		if err != nil || bounds == nil {
+			return err
+		}
+
+		sums.add(score, b)
+		_, err = fmt.Fprintf(bounds, "%v\t%v\t%v\t%v\n", row.ID, score, b.Lower, b.Upper)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = bw.Flush()
+	if err != nil {
+		return err
+	}
+
+	err = fd.Close()
+	if err != nil {
+		return err
+	}
+
+	if bounds != nil {
+		err = bounds.Flush()
+		if err != nil {
+			return err
+		}
+
+		err = bfd.Close()
+		if err != nil {
+			return err
+		}
+
+		err = dsc.writeAccuracy(fd.Name(), sums.accuracy())
+		if err != nil {
+			return err
+		}
+	}
+
+	timings.Write = time.Since(t1)
+	timings.Total = time.Since(total)
+	dsc.logf("write results to file done in: %v\n", timings.Write)
+	dsc.logf("%v\n", timings.Total)
+
+	m, err := dsc.newManifest(total, inputFile, fd.Name(), approximate, ps.gridSize, stats, points, timings)
+	if err != nil {
+		return err
+	}
+	m.Streaming = true
+	m.AutoGridSize = len(gridSize) == 0
+	return dsc.writeManifest(fd.Name(), m)
+}
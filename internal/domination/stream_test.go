@@ -0,0 +1,176 @@
+package domination
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCalcStreamMatchesCalc(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	rows := randomRows(r, 400, 3, 30, true)
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "nodes.csv")
+
+	sb := strings.Builder{}
+	sb.WriteString("id,a,b,c\n")
+	for i, row := range rows {
+		switch i % 50 {
+		case 7:
+			// imputed, or skipped with SkipRow
+			sb.WriteString(fmt.Sprintf("%v,%v,x,%v\n", row.ID, row.Attrs[0], row.Attrs[2]))
+		case 9:
+			// skipped either way
+			sb.WriteString(fmt.Sprintf("y,%v,%v,%v\n", row.Attrs[0], row.Attrs[1], row.Attrs[2]))
+		default:
+			sb.WriteString(fmt.Sprintf("%v,%v,%v,%v\n", row.ID, row.Attrs[0], row.Attrs[1], row.Attrs[2]))
+		}
+	}
+	content := sb.String()
+
+	// the rows of some ids again, with other attributes, which
+	// both count as points but only the last one keeps its id
+	for i, row := range rows {
+		if i%50 == 13 {
+			content += fmt.Sprintf("%v,%v,%v,%v\n", row.ID, r.Intn(30), r.Intn(30), r.Intn(30))
+		}
+	}
+
+	read := func(name string) string {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	for _, repeated := range []bool{false, true} {
+		data := sb.String()
+		if repeated {
+			data = content
+		}
+		if err := os.WriteFile(input, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, strictness := range []Strictness{SkipRow, Impute} {
+			for _, approximate := range []bool{false, true} {
+				cr := &CSVDatasetReader{
+					Columns: ColumnMapping{
+						Header: true,
+						ID:     ColumnIndex(0),
+						Attrs:  []Column{ColumnIndex(1), ColumnIndex(2), ColumnIndex(3)},
+					},
+					Strictness: strictness,
+					Directions: []Direction{Maximize, Minimize, Maximize},
+				}
+				dsc := &DominationScoreCalculator{Workers: 2, Directions: cr.Directions}
+
+				err := dsc.Calc(cr, input, filepath.Join(dir, "calc.txt"), approximate, []int{4, 4, 4})
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = dsc.CalcStream(cr, input, filepath.Join(dir, "stream.txt"), approximate, []int{4, 4, 4})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				name := fmt.Sprintf("repeated %v strictness %v approximate %v", repeated, strictness, approximate)
+				calc, stream := read("calc.txt"), read("stream.txt")
+				if repeated {
+					// a line for every row, the last one of
+					// an id with the score Calc writes
+					if got, want := strings.Count(stream, "\n"), strings.Count(calc, "\n")+len(rows)/50; got != want {
+						t.Errorf("%v: got %v streamed lines, want %v", name, got, want)
+					}
+					stream = lastLines(stream)
+				}
+
+				// the ids of the file are in order, so both
+				// write the scores in the same order
+				if calc != stream {
+					t.Errorf("%v: streamed scores differ", name)
+				}
+				if !approximate {
+					continue
+				}
+
+				calc, stream = read("calc_bounds.txt"), read("stream_bounds.txt")
+				if repeated {
+					stream = lastLines(stream)
+				}
+				if calc != stream {
+					t.Errorf("%v: streamed bounds differ", name)
+				}
+				if !repeated && read("calc_accuracy.txt") != read("stream_accuracy.txt") {
+					t.Errorf("%v: streamed accuracy differs", name)
+				}
+			}
+		}
+	}
+}
+
+// lastLines keeps the last line of every id of the scores written by
+// CalcStream, in id order, the way Calc writes them
+func lastLines(scores string) string {
+	lines := strings.Split(strings.TrimSuffix(scores, "\n"), "\n")
+
+	last := map[int]string{}
+	for _, line := range lines[1:] {
+		id, err := strconv.Atoi(strings.SplitN(line, "\t", 2)[0])
+		if err != nil {
+			panic(err)
+		}
+		last[id] = line
+	}
+
+	ids := []int{}
+	for id := range last {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	res := lines[0] + "\n"
+	for _, id := range ids {
+		res += last[id] + "\n"
+	}
+	return res
+}
+
+func TestReadPoints(t *testing.T) {
+	filename := writeFile(t, "1\t1\t2\n2\t1\t2\n3\t4\t0\n")
+
+	cr := &CSVDatasetReader{
+		Columns: ColumnMapping{
+			Delimiter: "\t",
+			ID:        ColumnIndex(0),
+			Attrs:     []Column{ColumnIndex(1), ColumnIndex(2)},
+		},
+	}
+
+	stats, points, err := cr.ReadPoints(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Count != 3 || len(points) != 2 {
+		t.Errorf("got %v rows and %v unique points, want 3 and 2", stats.Count, len(points))
+	}
+
+	ids := []int{}
+	err = cr.StreamRows(filename, stats, func(row DataRow) error {
+		ids = append(ids, row.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ids) != "[1 2 3]" {
+		t.Errorf("got rows %v, want [1 2 3]", ids)
+	}
+}
@@ -0,0 +1,231 @@
+package domination
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Algorithm selects how the exact scores are counted
+type Algorithm int
+
+const (
+	// Auto counts with DivideAndConquer for 2 and 3 attributes
+	// and with Grid for any other number
+	Auto Algorithm = iota
+
+	// Grid compares the points of the cells of the grid,
+	// one by one within the partially dominated cells
+	Grid
+
+	// DivideAndConquer counts in O(n log n) time with a sweep over
+	// a Fenwick tree for 2 attributes and in O(n log² n) time with
+	// CDQ divide and conquer for 3. It falls back to Grid for any
+	// other number of attributes.
+	DivideAndConquer
+
+	// KDTree queries a k-d tree of the unique points, see NewKDTree
+	KDTree
+)
+
+var algorithmNames = []string{"auto", "grid", "divide-and-conquer", "kd-tree"}
+
+// String returns the name of the algorithm
+func (a Algorithm) String() string {
+	if a >= 0 && int(a) < len(algorithmNames) {
+		return algorithmNames[a]
+	}
+	return fmt.Sprintf("Algorithm(%d)", int(a))
+}
+
+// ParseAlgorithm converts the algorithm names used in the settings.json
+// files to an Algorithm. An empty name is Auto.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	if name == "" {
+		return Auto, nil
+	}
+
+	for i, n := range algorithmNames {
+		if strings.EqualFold(name, n) {
+			return Algorithm(i), nil
+		}
+	}
+	return Auto, fmt.Errorf("unknown algorithm %q, should be one of %v", name, strings.Join(algorithmNames, ", "))
+}
+
+// exactAlgorithm returns the algorithm that counts the
+// exact scores of points with the given dimensions
+func (dsc *DominationScoreCalculator) exactAlgorithm(dimensions int) Algorithm {
+	switch dsc.Algorithm {
+	case Grid, KDTree:
+		return dsc.Algorithm
+	}
+
+	if dimensions < 2 || dimensions > 3 {
+		return Grid
+	}
+	return DivideAndConquer
+}
+
+// countDominated returns the number of rows every unique point
+// dominates, in the order of points. The points are unique and
+// have 2 or 3 attributes, where larger is better.
+//
+// Since the points are unique, a point dominates every other point
+// lower or equal in all attributes, so both counts are weighted
+// lower orthant counts: with the points sorted in increasing order,
+// only the points before p can be lower or equal to p.
+func countDominated(points []DataPoint) []int {
+	order := make([]int, len(points))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := points[order[i]].Attrs, points[order[j]].Attrs
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+
+	items := make([]sweepItem, len(points))
+	for i, p := range order {
+		items[i] = sweepItem{point: p, count: points[p].Count}
+	}
+
+	// replace the values of the last two attributes by their
+	// ranks, which keeps the ties and indexes the Fenwick tree
+	d := len(points[0].Attrs)
+	ys := rank(points, order, d-2)
+	zs := rank(points, order, d-1)
+	for i := range items {
+		items[i].y, items[i].z = ys[i], zs[i]
+	}
+
+	if d == 2 {
+		sweep(items)
+	} else {
+		byIndex := make([]*sweepItem, len(items))
+		for i := range items {
+			byIndex[i] = &items[i]
+		}
+		tree := newFenwick(len(items))
+		cdq(byIndex, make([]*sweepItem, len(items)), tree)
+	}
+
+	res := make([]int, len(points))
+	for _, it := range items {
+		res[it.point] = it.score
+	}
+	return res
+}
+
+// sweepItem is a unique point during the count, with the ranks of its
+// last two attributes
+type sweepItem struct {
+	point int
+	y, z  int
+	count int
+	score int
+}
+
+// rank returns the rank of attribute k of every point in order,
+// counting from 1, with equal values sharing a rank
+func rank(points []DataPoint, order []int, k int) []int {
+	values := make([]float64, len(order))
+	for i, p := range order {
+		values[i] = points[p].Attrs[k]
+	}
+
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	distinct := sorted[:0]
+	for i, v := range sorted {
+		if i == 0 || v != sorted[i-1] {
+			distinct = append(distinct, v)
+		}
+	}
+
+	ranks := make([]int, len(values))
+	for i, v := range values {
+		ranks[i] = 1 + sort.SearchFloat64s(distinct, v)
+	}
+	return ranks
+}
+
+// sweep counts the 2 dimensional scores. items are sorted by the first
+// attribute, and z holds the ranks of the second. Every item is added
+// to the tree before it is queried, since its lower orthant includes
+// itself, and its own count is taken off the sum.
+func sweep(items []sweepItem) {
+	tree := newFenwick(len(items))
+	for i := range items {
+		tree.add(items[i].z, items[i].count)
+		items[i].score = tree.sum(items[i].z) - items[i].count
+	}
+}
+
+// cdq counts the 3 dimensional scores by divide and conquer. items
+// are sorted by all three attributes, y and z hold the ranks of the
+// last two, so that the items before p lower or equal in y and z are
+// the ones p dominates. Every level adds the counts of the first half
+// to the scores of the second, merging both halves by y, which leaves
+// items sorted by y for the level above. buf is scratch space as long
+// as items and tree is empty between calls.
+func cdq(items []*sweepItem, buf []*sweepItem, tree *fenwick) {
+	if len(items) < 2 {
+		return
+	}
+
+	mid := len(items) / 2
+	left, right := items[:mid], items[mid:]
+	cdq(left, buf[:mid], tree)
+	cdq(right, buf[mid:], tree)
+
+	merged := buf[:0]
+	i := 0
+	for _, r := range right {
+		// equal y is lower or equal, so the left items go first
+		for i < len(left) && left[i].y <= r.y {
+			tree.add(left[i].z, left[i].count)
+			merged = append(merged, left[i])
+			i++
+		}
+		r.score += tree.sum(r.z)
+		merged = append(merged, r)
+	}
+
+	for _, l := range left[:i] {
+		tree.add(l.z, -l.count)
+	}
+	merged = append(merged, left[i:]...)
+
+	copy(items, merged)
+}
+
+// fenwick is a binary indexed tree of sums over the ranks 1 to n
+type fenwick []int
+
+func newFenwick(n int) *fenwick {
+	f := make(fenwick, n+1)
+	return &f
+}
+
+// add adds v at rank i
+func (f *fenwick) add(i int, v int) {
+	for ; i < len(*f); i += i & -i {
+		(*f)[i] += v
+	}
+}
+
+// sum returns the sum of ranks 1 to i
+func (f *fenwick) sum(i int) int {
+	s := 0
+	for ; i > 0; i -= i & -i {
+		s += (*f)[i]
+	}
+	return s
+}
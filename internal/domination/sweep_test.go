@@ -0,0 +1,42 @@
+package domination
+
+import "testing"
+
+func TestExactAlgorithm(t *testing.T) {
+	tests := []struct {
+		algorithm  Algorithm
+		dimensions int
+		want       Algorithm
+	}{
+		{Auto, 1, Grid},
+		{Auto, 2, DivideAndConquer},
+		{Auto, 3, DivideAndConquer},
+		{Auto, 4, Grid},
+		{Grid, 2, Grid},
+		{DivideAndConquer, 3, DivideAndConquer},
+		{DivideAndConquer, 5, Grid},
+		{KDTree, 2, KDTree},
+		{KDTree, 4, KDTree},
+	}
+
+	for _, tt := range tests {
+		dsc := &DominationScoreCalculator{Algorithm: tt.algorithm}
+		if got := dsc.exactAlgorithm(tt.dimensions); got != tt.want {
+			t.Errorf("%v with %v attributes: got %v, want %v", tt.algorithm, tt.dimensions, got, tt.want)
+		}
+	}
+}
+
+func TestFenwick(t *testing.T) {
+	f := newFenwick(10)
+	f.add(3, 2)
+	f.add(7, 5)
+	f.add(10, 1)
+	f.add(3, -1)
+
+	for i, want := range []int{0, 0, 0, 1, 1, 1, 1, 6, 6, 6, 7} {
+		if got := f.sum(i); got != want {
+			t.Errorf("sum(%v) = %v, want %v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package domination
+
+import (
+	"container/heap"
+	"sort"
+	"time"
+)
+
+// RankedRow is a row id with its domination score
+type RankedRow struct {
+	ID    int
+	Score int
+}
+
+// ranksBefore orders rows by descending score and, for equal scores,
+// by descending id, the same order read_dom gives to the domination
+// file in the community discovery scripts
+func ranksBefore(a, b RankedRow) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return a.ID > b.ID
+}
+
+// rankHeap keeps the k best rows seen so far with the worst on top
+type rankHeap []RankedRow
+
+func (h rankHeap) Len() int            { return len(h) }
+func (h rankHeap) Less(i, j int) bool  { return ranksBefore(h[j], h[i]) }
+func (h rankHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *rankHeap) Push(x interface{}) { *h = append(*h, x.(RankedRow)) }
+func (h *rankHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// TopK returns the k rows with the highest domination score, best first
+func (dsc *DominationScoreCalculator) TopK(rows []DataRow, k int, gridSize []int) ([]RankedRow, error) {
+	r, stats, unique, err := readRows(&sliceIterator{rows: rows}, gridSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return dsc.topK(r, stats, unique, k, gridSize)
+}
+
+// TopKFile reads the dataset in inputFile with dataReader and returns
+// the k rows with the highest domination score, best first
+func (dsc *DominationScoreCalculator) TopKFile(dataReader DatasetReader, inputFile string, k int, gridSize []int) ([]RankedRow, error) {
+	t1 := time.Now()
+	rows, stats, unique, err := dataReader.ReadDataset(inputFile)
+	if err != nil {
+		return nil, err
+	}
+	dsc.logf("reading done in: %v\n", time.Since(t1))
+
+	return dsc.topK(rows, stats, unique, k, gridSize)
+}
+
+// topK finds the k best rows without scoring every unique point.
+//
+// The score of every point in a cell lies between the number of points
+// in the cells lower in every coordinate and that number plus the points
+// in the lower or equal cells, minus the point itself, which stands for
+// at least as many rows as the smallest point of the cell. The cells are
+// scored exactly in decreasing order of that upper bound, and the search
+// stops at the first cell whose upper bound is lower than the k-th best
+// score found so far.
+func (dsc *DominationScoreCalculator) topK(rows map[int]DataRow, stats *DataStats, unique []DataPoint, k int, gridSize []int) ([]RankedRow, error) {
+	o, err := dsc.orient(stats)
+	if err != nil {
+		return nil, err
+	}
+
+	if k <= 0 {
+		return []RankedRow{}, nil
+	}
+
+	stats = o.dataStats()
+	points := o.points(unique)
+	gridSize, err = dsc.gridSizeFor(stats, points, false, gridSize)
+	if err != nil {
+		return nil, err
+	}
+
+	t1 := time.Now()
+
+	gi := newGridIndex(points, newGridLayout(stats, gridSize, dsc.GridMode))
+
+	ids := make(map[pointKey][]int, len(unique))
+	for id, row := range rows {
+		key := newPointKey(o.attrs(row.Attrs))
+		ids[key] = append(ids[key], id)
+	}
+
+	bounds := make([]cellBounds, len(gi.cells))
+	for i := range gi.cells {
+		bounds[i] = boundCell(i, gi)
+	}
+
+	order := make([]int, len(gi.cells))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return bounds[order[i]].upper > bounds[order[j]].upper
+	})
+
+	top := &rankHeap{}
+	scanned := 0
+
+	for _, i := range order {
+		b := &bounds[i]
+		if top.Len() == k && b.upper < (*top)[0].Score {
+			break
+		}
+		scanned++
+
+		for _, n := range gi.cells[i].points {
+			score := b.base
+			for _, j := range b.partial {
+				score += partialScore(n, gi.cells[j].points)
+			}
+
+			for _, id := range ids[newPointKey(n.Attrs)] {
+				r := RankedRow{ID: id, Score: score}
+
+				if top.Len() < k {
+					heap.Push(top, r)
+				} else if ranksBefore(r, (*top)[0]) {
+					(*top)[0] = r
+					heap.Fix(top, 0)
+				}
+			}
+		}
+	}
+
+	res := make([]RankedRow, top.Len())
+	for i := len(res) - 1; i >= 0; i-- {
+		res[i] = heap.Pop(top).(RankedRow)
+	}
+
+	dsc.logf("cells: %v\tscored: %v\ttop-%v done in: %v\n", len(gi.cells), scanned, k, time.Since(t1))
+
+	return res, nil
+}
+
+// cellBounds holds the bounds of the scores of the points of a cell
+type cellBounds struct {
+	// base is the number of rows in the cells lower in every
+	// coordinate, which every point of the cell dominates
+	base int
+
+	// partial holds the indexes of the lower or equal cells,
+	// whose points are compared one by one
+	partial []int
+
+	upper int
+}
+
+// boundCell returns the bounds of the scores of the points of cell
+// gi.cells[i], scanning the grid the same way as scanCell
+func boundCell(i int, gi *gridIndex) cellBounds {
+	b := cellBounds{}
+	partial := 0
+
+	b.base = gi.below(i, gi.cells[i].coords, func(j int) {
+		b.partial = append(b.partial, j)
+		partial += gi.cells[j].count
+	})
+
+	least := gi.cells[i].points[0].Count
+	for _, p := range gi.cells[i].points {
+		if p.Count < least {
+			least = p.Count
+		}
+	}
+	b.upper = b.base + partial - least
+
+	return b
+}
@@ -0,0 +1,64 @@
+package domination
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func referenceTopK(rows []DataRow, k int, directions ...Direction) []RankedRow {
+	scores := ReferenceScores(rows, directions...)
+
+	res := []RankedRow{}
+	for id, score := range scores {
+		res = append(res, RankedRow{ID: id, Score: score})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return ranksBefore(res[i], res[j])
+	})
+
+	if k < len(res) {
+		res = res[:k]
+	}
+	return res
+}
+
+// TestBoundCell checks that the scores of the points of every cell
+// lie within its bounds, and that the upper bound is reached when the
+// point of a cell with the fewest rows dominates every other one
+func TestBoundCell(t *testing.T) {
+	r := rand.New(rand.NewSource(11))
+
+	rows := randomRows(r, 400, 3, 6, false)
+	_, stats, unique, err := readRows(&sliceIterator{rows: rows}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ReferenceScores(rows)
+	scores := map[pointKey]int{}
+	for _, row := range rows {
+		scores[newPointKey(row.Attrs)] = want[row.ID]
+	}
+
+	gi := newGridIndex(unique, newGridLayout(stats, gridOf(3, 3), EqualWidth))
+	for i, cell := range gi.cells {
+		b := boundCell(i, gi)
+		for _, p := range cell.points {
+			score := scores[newPointKey(p.Attrs)]
+			if score < b.base || score > b.upper {
+				t.Errorf("cell %v: point %v scores %v, out of [%v, %v]", cell.coords, p.Attrs, score, b.base, b.upper)
+			}
+		}
+	}
+
+	// the top cell holds 3 rows of (1, 1) and 1 of (2, 2)
+	gi = &gridIndex{cells: []gridCell{
+		{coords: []float64{1, 1}, sum: 2, count: 4, points: []DataPoint{
+			{Attrs: []float64{1, 1}, Count: 3},
+			{Attrs: []float64{2, 2}, Count: 1},
+		}},
+	}}
+	if b := boundCell(0, gi); b.base != 0 || b.upper != 3 || len(b.partial) != 1 {
+		t.Errorf("got bounds %+v, want an upper bound of 3", b)
+	}
+}
@@ -0,0 +1,184 @@
+// Package generator draws the synthetic datasets of cmd/datasetGenerator,
+// so that the benchmarks of the domination package score the same
+// distributions the command writes.
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// The dataset types
+const (
+	Uniform        = "UNIFORM"
+	Correlated     = "CORRELATED"
+	AntiCorrelated = "ANTI_CORRELATED"
+	Clustered      = "CLUSTERED"
+)
+
+// Params holds the dataset type and the parameters of its distribution:
+// the values are drawn from [0, ValueRange), Correlation sets how close
+// the AntiCorrelated points lie to their plane, and a Clustered dataset
+// has Clusters clusters with a standard deviation of Spread, both as
+// fractions of the value range
+type Params struct {
+	Type       string
+	Dimensions int
+
+	ValueRange  int
+	Correlation float64
+	Clusters    int
+	Spread      float64
+}
+
+// DefaultParams returns the parameters cmd/datasetGenerator uses for
+// the datasets of datasetType when its settings leave them out
+func DefaultParams(datasetType string, dimensions int) Params {
+	return Params{
+		Type:       datasetType,
+		Dimensions: dimensions,
+
+		ValueRange:  255,
+		Correlation: 0.9,
+		Clusters:    10,
+		Spread:      0.05,
+	}
+}
+
+// Generator draws the points of a dataset
+type Generator struct {
+	r *rand.Rand
+	p Params
+
+	// centers of the clusters of a Clustered dataset
+	centers [][]float64
+}
+
+// New returns a Generator drawing from r. The same r and p give the
+// same points.
+func New(r *rand.Rand, p Params) (*Generator, error) {
+	g := &Generator{r: r, p: p}
+
+	switch p.Type {
+	case Uniform, Correlated, AntiCorrelated:
+	case Clustered:
+		if p.Clusters < 1 {
+			return nil, fmt.Errorf("a clustered dataset needs at least 1 cluster, got %v", p.Clusters)
+		}
+		g.centers = clusterCenters(r, p.Clusters, p.Dimensions)
+	default:
+		return nil, fmt.Errorf("unknown dataset type %q, expected UNIFORM, CORRELATED, ANTI_CORRELATED or CLUSTERED", p.Type)
+	}
+
+	return g, nil
+}
+
+// Point returns the next point of the dataset
+func (g *Generator) Point() []int {
+	p := g.p
+
+	switch p.Type {
+	case Correlated:
+		// the means and the spread are set for a range of 255
+		scale := float64(p.ValueRange) / 255
+
+		m := g.r.NormFloat64()
+		for m < 0 {
+			m = g.r.NormFloat64()
+		}
+		return correlated(g.r, float64(m*50)*scale, 25*scale, p.Dimensions)
+	case AntiCorrelated:
+		return antiCorrelated(g.r, p.Dimensions, p.ValueRange, p.Correlation)
+	case Clustered:
+		return clustered(g.r, g.centers, p.ValueRange, p.Spread)
+	}
+	return uniform(g.r, p.Dimensions, p.ValueRange)
+}
+
+func correlated(r *rand.Rand, mean float64, spread float64, d int) []int {
+	res := make([]int, d)
+	for i := range res {
+
+		nf := r.NormFloat64()
+		for nf < 0 {
+			nf = r.NormFloat64()
+		}
+
+		res[i] = int(nf*spread + mean)
+	}
+	return res
+}
+
+func uniform(r *rand.Rand, d int, valueRange int) []int {
+	res := make([]int, d)
+	for i := range res {
+		res[i] = int(r.Int31n(int32(valueRange)))
+	}
+	return res
+}
+
+// antiCorrelated returns a point close to a plane where the values
+// add up to the same sum, so that a point good in some dimensions is
+// bad in the others. The plane is drawn around the middle of the range
+// with a standard deviation of (1-correlation)/2 of the range, and the
+// point uniformly on the plane.
+func antiCorrelated(r *rand.Rand, d int, valueRange int, correlation float64) []int {
+	x := make([]float64, d)
+	for {
+		plane := 0.5 + r.NormFloat64()*(1-correlation)/2
+
+		sum := 0.0
+		for i := range x {
+			x[i] = r.Float64()
+			sum += x[i]
+		}
+
+		shift := plane - sum/float64(d)
+		inside := true
+		for i := range x {
+			x[i] += shift
+			if x[i] < 0 || x[i] >= 1 {
+				inside = false
+			}
+		}
+
+		if inside {
+			break
+		}
+	}
+
+	res := make([]int, d)
+	for i := range res {
+		res[i] = int(x[i] * float64(valueRange))
+	}
+	return res
+}
+
+// clusterCenters returns k centers drawn uniformly
+// from the unit cube of d dimensions
+func clusterCenters(r *rand.Rand, k int, d int) [][]float64 {
+	centers := make([][]float64, k)
+	for i := range centers {
+		centers[i] = make([]float64, d)
+		for j := range centers[i] {
+			centers[i][j] = r.Float64()
+		}
+	}
+	return centers
+}
+
+// clustered returns a point around one of the centers, drawn normally
+// with a standard deviation of spread of the range in every dimension
+func clustered(r *rand.Rand, centers [][]float64, valueRange int, spread float64) []int {
+	c := centers[r.Intn(len(centers))]
+
+	res := make([]int, len(c))
+	for i := range res {
+		x := c[i] + r.NormFloat64()*spread
+		for x < 0 || x >= 1 {
+			x = c[i] + r.NormFloat64()*spread
+		}
+		res[i] = int(x * float64(valueRange))
+	}
+	return res
+}
@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// points returns n points of the dataset of p, drawn with seed
+func points(t *testing.T, seed int64, p Params, n int) (*Generator, [][]int) {
+	t.Helper()
+
+	g, err := New(rand.New(rand.NewSource(seed)), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := make([][]int, n)
+	for i := range res {
+		res[i] = g.Point()
+		if len(res[i]) != p.Dimensions {
+			t.Fatalf("got point %v for %v dimensions", res[i], p.Dimensions)
+		}
+		// the correlated values are normal around their
+		// mean, so they can exceed the value range
+		for _, v := range res[i] {
+			if v < 0 || (v >= p.ValueRange && p.Type != Correlated) {
+				t.Fatalf("got point %v outside [0, %v)", res[i], p.ValueRange)
+			}
+		}
+	}
+	return g, res
+}
+
+// correlation returns the Pearson correlation of attributes i and j
+func correlation(points [][]int, i, j int) float64 {
+	n := float64(len(points))
+
+	var si, sj, sii, sjj, sij float64
+	for _, p := range points {
+		x, y := float64(p[i]), float64(p[j])
+		si += x
+		sj += y
+		sii += x * x
+		sjj += y * y
+		sij += x * y
+	}
+
+	cov := sij/n - si/n*sj/n
+	return cov / math.Sqrt((sii/n-si/n*si/n)*(sjj/n-sj/n*sj/n))
+}
+
+func TestAntiCorrelated(t *testing.T) {
+	for d := 2; d <= 4; d++ {
+		_, pts := points(t, int64(d), DefaultParams(AntiCorrelated, d), 5000)
+
+		for i := 0; i < d; i++ {
+			for j := i + 1; j < d; j++ {
+				if c := correlation(pts, i, j); c >= 0 {
+					t.Errorf("%vD: attributes %v and %v have a correlation of %v, want it negative", d, i, j, c)
+				}
+			}
+		}
+	}
+
+	// the correlation of the attributes of the other
+	// datasets is not negative, or not as strongly
+	_, pts := points(t, 1, DefaultParams(Correlated, 2), 5000)
+	if c := correlation(pts, 0, 1); c <= 0 {
+		t.Errorf("correlated: got a correlation of %v, want it positive", c)
+	}
+}
+
+func TestClustered(t *testing.T) {
+	for d := 1; d <= 4; d++ {
+		p := DefaultParams(Clustered, d)
+		p.Clusters = 3
+		p.ValueRange = 1000
+
+		g, pts := points(t, int64(d), p, 5000)
+		if len(g.centers) != p.Clusters {
+			t.Fatalf("%vD: got %v centers, want %v", d, len(g.centers), p.Clusters)
+		}
+
+		// every point lies within 5 standard deviations of a
+		// center, a value range unit added for the rounding down
+		limit := 5*p.Spread + 1/float64(p.ValueRange)
+		for _, pt := range pts {
+			near := false
+			for _, c := range g.centers {
+				inside := true
+				for i, v := range pt {
+					if math.Abs(float64(v)/float64(p.ValueRange)-c[i]) > limit {
+						inside = false
+					}
+				}
+				near = near || inside
+			}
+			if !near {
+				t.Errorf("%vD: point %v is not within %v of any of the centers %v", d, pt, limit, g.centers)
+				break
+			}
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(rand.New(rand.NewSource(1)), DefaultParams("GAUSSIAN", 2)); err == nil {
+		t.Error("expected an error for an unknown dataset type")
+	}
+
+	p := DefaultParams(Clustered, 2)
+	p.Clusters = 0
+	if _, err := New(rand.New(rand.NewSource(1)), p); err == nil {
+		t.Error("expected an error for a clustered dataset without clusters")
+	}
+
+	// the same seed draws the same points
+	for _, datasetType := range []string{Uniform, Correlated, AntiCorrelated, Clustered} {
+		_, a := points(t, 7, DefaultParams(datasetType, 3), 100)
+		_, b := points(t, 7, DefaultParams(datasetType, 3), 100)
+		for i := range a {
+			for j := range a[i] {
+				if a[i][j] != b[i][j] {
+					t.Fatalf("%v: point %v is %v and %v", datasetType, i, a[i], b[i])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Package settings checks the settings.json files of the commands
+// before they run, so that every problem is reported at once instead
+// of one at a time, or as a panic halfway through a run.
+package settings
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ngeorgiadis/community-discovery/internal/domination"
+)
+
+// Problems collects what is wrong with a settings file
+type Problems struct {
+	list []string
+}
+
+// Addf adds a problem
+func (p *Problems) Addf(format string, a ...interface{}) {
+	p.list = append(p.list, fmt.Sprintf(format, a...))
+}
+
+// Check adds err as a problem of setting, unless it is nil
+func (p *Problems) Check(setting string, err error) {
+	if err != nil {
+		p.Addf("%v: %v", setting, err)
+	}
+}
+
+// Err returns the problems as an *Error, or nil when there are none
+func (p *Problems) Err() error {
+	if len(p.list) == 0 {
+		return nil
+	}
+	return &Error{Problems: p.list}
+}
+
+// Error lists every problem of a settings file
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%v invalid settings:", len(e.Problems))
+	for _, p := range e.Problems {
+		sb.WriteString("\n\t")
+		sb.WriteString(p)
+	}
+	return sb.String()
+}
+
+// Positive checks that the value of setting is at least 1
+func (p *Problems) Positive(setting string, v int) {
+	if v < 1 {
+		p.Addf("%v is %v, should be at least 1", setting, v)
+	}
+}
+
+// Between checks that the value of setting is from min to max
+func (p *Problems) Between(setting string, v float64, min float64, max float64) {
+	if !(v >= min && v <= max) {
+		p.Addf("%v is %v, should be from %v to %v", setting, v, min, max)
+	}
+}
+
+// OneOf checks that the value of setting is one of names. An empty
+// value picks the default of the setting and always fits.
+func (p *Problems) OneOf(setting string, value string, names ...string) {
+	if value == "" {
+		return
+	}
+
+	for _, name := range names {
+		if value == name {
+			return
+		}
+	}
+	p.Addf("%v: unknown value %q, should be one of %v", setting, value, strings.Join(names, ", "))
+}
+
+// GridSize checks that gridSize has a cell count of at least 1 for
+// every one of the dimensions. An empty grid size is picked
+// automatically and always fits.
+func (p *Problems) GridSize(setting string, gridSize []int, dimensions int) {
+	if len(gridSize) == 0 {
+		return
+	}
+
+	if dimensions > 0 && len(gridSize) != dimensions {
+		p.Addf("%v has %v entries for %v dimensions", setting, len(gridSize), dimensions)
+	}
+	for i, s := range gridSize {
+		if s < 1 {
+			p.Addf("%v entry %v is %v, should be at least 1", setting, i, s)
+		}
+	}
+}
+
+// Directions checks that names are preference directions, one for
+// every one of the dimensions. No names maximize every attribute.
+func (p *Problems) Directions(setting string, names []string, dimensions int) {
+	if len(names) == 0 {
+		return
+	}
+
+	if dimensions > 0 && len(names) != dimensions {
+		p.Addf("%v has %v entries for %v dimensions", setting, len(names), dimensions)
+	}
+	_, err := domination.ParseDirections(names)
+	p.Check(setting, err)
+}
+
+// InputFile checks that filename is a file that can be read. An empty
+// filename is a problem unless the setting is optional.
+func (p *Problems) InputFile(setting string, filename string, optional bool) {
+	if filename == "" {
+		if !optional {
+			p.Addf("%v is not set", setting)
+		}
+		return
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		p.Addf("%v: %v", setting, err)
+		return
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		p.Addf("%v: %v", setting, err)
+		return
+	}
+	if fi.IsDir() {
+		p.Addf("%v: %v is a directory", setting, filename)
+	}
+}
+
+// OutputDir checks that dir is a directory or can be created as one:
+// the closest of it and its parents that exists should be a directory.
+// An empty dir is the working directory.
+func (p *Problems) OutputDir(setting string, dir string) {
+	if dir == "" {
+		return
+	}
+
+	for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+		fi, err := os.Stat(d)
+		if err == nil {
+			if !fi.IsDir() {
+				p.Addf("%v: %v is not a directory", setting, d)
+			}
+			return
+		}
+
+		if filepath.Dir(d) == d {
+			return
+		}
+	}
+}
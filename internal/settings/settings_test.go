@@ -0,0 +1,75 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProblems(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "nodes.csv")
+	if err := os.WriteFile(file, []byte("id\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	ok := &Problems{}
+	ok.Positive("size", 1)
+	ok.Between("correlation", 1, 0, 1)
+	ok.OneOf("mode", "skyline", "score", "skyline")
+	ok.OneOf("mode", "", "score", "skyline")
+	ok.GridSize("gridSize", []int{2, 3}, 2)
+	ok.GridSize("gridSize", nil, 4)
+	ok.Directions("directions", []string{"max", "min"}, 2)
+	ok.InputFile("nodes", file, false)
+	ok.InputFile("edges", "", true)
+	ok.OutputDir("output", dir)
+	ok.OutputDir("output", filepath.Join(dir, "new", "folder"))
+	ok.OutputDir("output", "")
+	if err := ok.Err(); err != nil {
+		t.Errorf("got %v", err)
+	}
+
+	p := &Problems{}
+	p.Positive("size", 0)
+	p.Between("correlation", -0.5, 0, 1)
+	p.OneOf("mode", "skylne", "score", "skyline")
+	p.GridSize("gridSize", []int{2, 0, 3}, 2)
+	p.Directions("directions", []string{"max", "up"}, 3)
+	p.InputFile("nodes", "", false)
+	p.InputFile("edges", filepath.Join(dir, "missing.csv"), true)
+	p.InputFile("folder", dir, false)
+	p.OutputDir("output", filepath.Join(file, "out"))
+
+	err := p.Err()
+	e, isError := err.(*Error)
+	if !isError {
+		t.Fatalf("got %v", err)
+	}
+
+	want := []string{
+		"size is 0",
+		"correlation is -0.5, should be from 0 to 1",
+		`mode: unknown value "skylne", should be one of score, skyline`,
+		"gridSize has 3 entries for 2 dimensions",
+		"gridSize entry 1 is 0",
+		"directions has 2 entries for 3 dimensions",
+		`directions: unknown direction "up"`,
+		"nodes is not set",
+		"edges: open",
+		"folder: " + dir + " is a directory",
+		"output: " + file + " is not a directory",
+	}
+	if len(e.Problems) != len(want) {
+		t.Fatalf("got %v problems, want %v:\n%v", len(e.Problems), len(want), err)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(e.Problems[i], w) {
+			t.Errorf("problem %v is %q, want it to start with %q", i, e.Problems[i], w)
+		}
+	}
+	if !strings.HasPrefix(err.Error(), "11 invalid settings:\n\tsize is 0") {
+		t.Errorf("got error %q", err)
+	}
+}